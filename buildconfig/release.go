@@ -2,10 +2,15 @@
 
 package buildconfig
 
-import "fmt"
+import (
+	"fmt"
+	"os"
+)
 
+// Reports a panic as a concise message instead of a stack trace, and exits with a failure status
 func PanicHandler() {
 	if r := recover(); r != nil {
-		fmt.Println(r)
+		fmt.Fprintf(os.Stderr, "jco: internal error: %v\n", r)
+		os.Exit(1)
 	}
 }
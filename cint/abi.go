@@ -0,0 +1,55 @@
+// Package cint simulates how C evaluates integer expressions: the types of literals and variables in a data model,
+// the integer promotions and usual arithmetic conversions, and which results are undefined or
+// implementation-defined
+package cint
+
+import (
+	"fmt"
+	"strings"
+)
+
+// A data model: the widths of the standard integer types in bits
+type ABI struct {
+	Name     string
+	Short    uint
+	Int      uint
+	Long     uint
+	LongLong uint
+
+	// Width of pointers, which decides the types of size_t, ptrdiff_t, intptr_t and uintptr_t
+	Pointer uint
+}
+
+// The data models that can be simulated, with the default first
+var ABIS = []ABI{
+	{"lp64", 16, 32, 64, 64, 64},
+	{"ilp32", 16, 32, 32, 64, 32},
+	{"llp64", 16, 32, 32, 64, 64},
+}
+
+// Returns the names of the data models, e.g. for the help text
+func ABINames() []string {
+	names := []string{}
+	for _, abi := range ABIS {
+		names = append(names, abi.Name)
+	}
+	return names
+}
+
+// Returns the data model with the given name, ignoring case
+func LookupABI(name string) (ABI, bool) {
+	for _, abi := range ABIS {
+		if strings.EqualFold(abi.Name, name) {
+			return abi, true
+		}
+	}
+	return ABI{}, false
+}
+
+// Returns the name and the widths of the types, e.g. "LP64: short 16, int 32, long 64, long long 64, pointer 64 bits"
+func (abi ABI) String() string {
+	return fmt.Sprintf(
+		"%s: short %d, int %d, long %d, long long %d, pointer %d bits",
+		strings.ToUpper(abi.Name), abi.Short, abi.Int, abi.Long, abi.LongLong, abi.Pointer,
+	)
+}
@@ -0,0 +1,551 @@
+package cint
+
+import (
+	"fmt"
+	"github.com/jonathangjertsen/jco-go/jco"
+	"math/big"
+	"strings"
+)
+
+// How well-defined the result of a step is, from best to worst
+type Behavior int
+
+const (
+	DEFINED Behavior = iota
+
+	// Well-defined, but not the mathematical result, e.g. unsigned wraparound or converting -1 to an unsigned type
+	WRAPPED
+
+	IMPLEMENTATION_DEFINED
+	UNDEFINED
+)
+
+// Binding strength of the infix operators, following C
+var C_PRECEDENCE = map[string]int{
+	"|":  1,
+	"^":  2,
+	"&":  3,
+	"==": 4,
+	"!=": 4,
+	"<":  5,
+	">":  5,
+	"<=": 5,
+	">=": 5,
+	"<<": 6,
+	">>": 6,
+	"+":  7,
+	"-":  7,
+	"*":  8,
+	"/":  8,
+	"%":  8,
+}
+
+// Operators made of two characters, which must be matched before the single characters
+var TWO_CHARACTER_OPERATORS = []string{"<<", ">>", "<=", ">=", "==", "!="}
+
+// A value during the evaluation, with the text it was written as
+type operand struct {
+	text string
+	typ  Type
+	x    *big.Int
+}
+
+// One step in the evaluation of a statement: a conversion or an operation
+type Step struct {
+	// The expression, e.g. "(int)a" for a conversion or "a + b" for an operation
+	Expression string
+
+	Type  Type
+	Value jco.Value
+
+	Behavior Behavior
+
+	// Why the behavior is not DEFINED
+	Note string
+}
+
+// A statement with the steps it was evaluated in
+type Statement struct {
+	Text  string
+	Steps []Step
+}
+
+// Evaluates statements and keeps track of the variables they declare
+type evaluator struct {
+	abi       ABI
+	variables map[string]operand
+	tokens    []string
+	pos       int
+	steps     []Step
+}
+
+// Returns x converted to the type, and whether that is well-defined. Conversions to unsigned types wrap around,
+// while it is implementation-defined what happens when a number does not fit in a signed type
+func conversionBehavior(x *big.Int, t Type) (Behavior, string, *big.Int) {
+	converted := t.Wrap(x)
+	switch {
+	case converted.Cmp(x) == 0:
+		return DEFINED, "", converted
+	case t.Signed:
+		return IMPLEMENTATION_DEFINED, fmt.Sprintf("%s does not fit in %s, most compilers wrap it to %s", x, t.Name, converted), converted
+	default:
+		return WRAPPED, fmt.Sprintf("%s wraps around to %s in %s", x, converted, t.Name), converted
+	}
+}
+
+// Returns whether the whole text is enclosed in one pair of parentheses, e.g. "(a + b)" but not "(a) + (b)"
+func isParenthesized(text string) bool {
+	if !strings.HasPrefix(text, "(") {
+		return false
+	}
+	depth := 0
+	for i, c := range text {
+		if c == '(' {
+			depth++
+		} else if c == ')' {
+			depth--
+		}
+		if depth == 0 {
+			return i == len(text)-1
+		}
+	}
+	return false
+}
+
+// Returns whether the character can be part of a number or a name
+func isWordChar(c byte) bool {
+	return c == '_' || ('0' <= c && c <= '9') || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
+
+// Returns the type and value of an integer literal like 0xf0, 42u or 1ULL, following the rules in C for which
+// types a literal can have depending on its suffix and whether it is decimal
+func parseLiteral(token string, abi ABI) (operand, error) {
+	digits := strings.TrimRight(token, "uUlL")
+	suffix := strings.ToLower(token[len(digits):])
+	x, ok := big.NewInt(0).SetString(digits, 0)
+	if !ok || strings.Contains(digits, "_") || strings.HasPrefix(strings.ToLower(digits), "0o") {
+		return operand{}, fmt.Errorf("invalid integer literal '%s'", token)
+	}
+	decimal := digits == "0" || digits[0] != '0'
+	unsignedSuffix := strings.Contains(suffix, "u")
+	longs := strings.Count(suffix, "l")
+	if len(suffix) != longs+strings.Count(suffix, "u") || strings.Count(suffix, "u") > 1 || longs > 2 || strings.Contains(suffix, "lul") || strings.Contains(token, "lL") || strings.Contains(token, "Ll") {
+		return operand{}, fmt.Errorf("invalid suffix on integer literal '%s'", token)
+	}
+
+	// Decimal literals without u only get signed types, other literals can also get the unsigned ones
+	for rank := RANK_INT + longs; rank <= RANK_LONG_LONG; rank++ {
+		if longs == 1 && rank == RANK_INT {
+			continue
+		}
+		for _, signed := range []bool{true, false} {
+			if (signed && unsignedSuffix) || (!signed && decimal && !unsignedSuffix) {
+				continue
+			}
+			t := abi.basic(rank, signed)
+			if t.Contains(x) {
+				return operand{token, t, x}, nil
+			}
+		}
+	}
+	return operand{}, fmt.Errorf("integer literal '%s' is too large for any integer type", token)
+}
+
+// Splits a statement into numbers, names, operators and parentheses
+func tokenize(statement string) ([]string, error) {
+	tokens := []string{}
+	for i := 0; i < len(statement); {
+		c := statement[i]
+		two := ""
+		for _, operator := range TWO_CHARACTER_OPERATORS {
+			if strings.HasPrefix(statement[i:], operator) {
+				two = operator
+			}
+		}
+		switch {
+		case c == ' ' || c == '\t' || c == '\r':
+			i++
+		case isWordChar(c):
+			start := i
+			for i < len(statement) && isWordChar(statement[i]) {
+				i++
+			}
+			tokens = append(tokens, statement[start:i])
+		case two != "":
+			tokens = append(tokens, two)
+			i += 2
+		case strings.IndexByte("+-*/%&|^~()=<>", c) >= 0:
+			tokens = append(tokens, statement[i:i+1])
+			i++
+		default:
+			return nil, fmt.Errorf("unexpected character '%c'", c)
+		}
+	}
+	return tokens, nil
+}
+
+// Evaluates the statements in the program, separated by semicolons or newlines. A statement is a declaration like
+// "uint8_t a = 0xf0", an assignment like "a = a << 4" or an expression like "a + b", where expressions are made of
+// integer literals, variables, casts and the arithmetic, bitwise, shift and comparison operators of C
+func Run(program string, abi ABI) ([]Statement, error) {
+	e := evaluator{abi: abi, variables: map[string]operand{}}
+	statements := []Statement{}
+	for _, text := range strings.FieldsFunc(program, func(r rune) bool { return r == ';' || r == '\n' }) {
+		text = strings.TrimSpace(text)
+		if text == "" {
+			continue
+		}
+		steps, err := e.run(text)
+		if err != nil {
+			return nil, fmt.Errorf("in '%s': %w", text, err)
+		}
+		statements = append(statements, Statement{Text: text, Steps: steps})
+	}
+	return statements, nil
+}
+
+// Consumes the next token if it is equal to the expected one
+func (e *evaluator) accept(expected string) bool {
+	if e.peek() == expected {
+		e.pos++
+		return true
+	}
+	return false
+}
+
+// Returns the behavior and note for a result which should be x but is wrapped to fit the type. Signed arithmetic
+// that overflows is undefined, while unsigned arithmetic wraps around
+func (e *evaluator) arithmeticBehavior(x *big.Int, t Type, text string) (Behavior, string) {
+	switch {
+	case t.Contains(x):
+		return DEFINED, ""
+	case t.Signed:
+		return UNDEFINED, fmt.Sprintf("signed overflow, %s is %s which does not fit in %s", text, x, t.Name)
+	default:
+		return WRAPPED, fmt.Sprintf("%s is %s which wraps around to %s in %s", text, x, t.Wrap(x), t.Name)
+	}
+}
+
+// Applies an infix operator to the operands, with the conversions that C does first
+func (e *evaluator) binary(symbol string, left, right operand) operand {
+	text := left.text + " " + symbol + " " + right.text
+	if symbol == "<<" || symbol == ">>" {
+		return e.shift(symbol, e.promote(left), e.promote(right), text)
+	}
+
+	t := e.abi.UsualArithmeticConversion(left.typ, right.typ)
+	l, r := e.convert(left, t, false).x, e.convert(right, t, false).x
+	x := big.NewInt(0)
+	behavior, note := DEFINED, ""
+	switch symbol {
+	case "+":
+		x.Add(l, r)
+		behavior, note = e.arithmeticBehavior(x, t, text)
+	case "-":
+		x.Sub(l, r)
+		behavior, note = e.arithmeticBehavior(x, t, text)
+	case "*":
+		x.Mul(l, r)
+		behavior, note = e.arithmeticBehavior(x, t, text)
+	case "/", "%":
+		if r.Sign() == 0 {
+			behavior, note = UNDEFINED, "division by zero"
+			break
+		}
+		if symbol == "/" {
+			x.Quo(l, r)
+		} else {
+			x.Rem(l, r)
+		}
+
+		// The quotient can only overflow for the most negative number divided by -1, in which case C leaves
+		// the remainder undefined as well
+		if quotient := big.NewInt(0).Quo(l, r); !t.Contains(quotient) {
+			behavior, note = UNDEFINED, fmt.Sprintf("signed overflow, %s / %s is %s which does not fit in %s", l, r, quotient, t.Name)
+		}
+	case "&":
+		x.And(l, r)
+	case "|":
+		x.Or(l, r)
+	case "^":
+		x.Xor(l, r)
+	default:
+		// Comparisons give an int which is 1 if the comparison is true
+		comparison := l.Cmp(r)
+		result := map[string]bool{
+			"<":  comparison < 0,
+			">":  comparison > 0,
+			"<=": comparison <= 0,
+			">=": comparison >= 0,
+			"==": comparison == 0,
+			"!=": comparison != 0,
+		}[symbol]
+		if result {
+			x.SetInt64(1)
+		}
+		t = e.abi.basic(RANK_INT, true)
+	}
+	return e.step(operand{text, t, t.Wrap(x)}, behavior, note)
+}
+
+// Returns the operand converted to the type, and records the conversion as a step unless the operand already has
+// the type. Conversions to unsigned types wrap around, while it is implementation-defined what happens when a
+// number does not fit in a signed type. Explicit conversions are written like casts, e.g. (uint8_t)x
+func (e *evaluator) convert(o operand, t Type, explicit bool) operand {
+	if o.typ.Is(t) && !explicit {
+		return o
+	}
+	behavior, note, converted := conversionBehavior(o.x, t)
+	text := o.text
+	if strings.Contains(text, " ") && !isParenthesized(text) {
+		text = "(" + text + ")"
+	}
+	cast := e.step(operand{"(" + t.Name + ")" + text, t, converted}, behavior, note)
+	if explicit {
+		return cast
+	}
+	return operand{o.text, t, converted}
+}
+
+// Consumes the next token, which must be equal to the expected one
+func (e *evaluator) expect(expected string) error {
+	if !e.accept(expected) {
+		if e.peek() == "" {
+			return fmt.Errorf("expected '%s' at the end", expected)
+		}
+		return fmt.Errorf("expected '%s', got '%s'", expected, e.peek())
+	}
+	return nil
+}
+
+// Returns whether the token starts the name of a type
+func (e *evaluator) isTypeStart(token string) bool {
+	if isTypeKeyword(token) {
+		return true
+	}
+	_, ok := e.abi.LookupType(token)
+	return ok && token != ""
+}
+
+// Parses a sequence of operands separated by infix operators which bind at least as strongly as minPrecedence
+func (e *evaluator) parseExpression(minPrecedence int) (operand, error) {
+	left, err := e.parseUnary()
+	if err != nil {
+		return operand{}, err
+	}
+	for {
+		symbol := e.peek()
+		precedence, isInfix := C_PRECEDENCE[symbol]
+		if !isInfix || precedence < minPrecedence {
+			return left, nil
+		}
+		e.pos++
+		right, err := e.parseExpression(precedence + 1)
+		if err != nil {
+			return operand{}, err
+		}
+		left = e.binary(symbol, left, right)
+	}
+}
+
+// Parses a literal, a variable or a parenthesized expression
+func (e *evaluator) parsePrimary() (operand, error) {
+	token := e.peek()
+	switch {
+	case token == "":
+		return operand{}, fmt.Errorf("unexpected end of statement")
+	case e.accept("("):
+		inner, err := e.parseExpression(1)
+		if err != nil {
+			return operand{}, err
+		}
+		inner.text = "(" + inner.text + ")"
+		return inner, e.expect(")")
+	case '0' <= token[0] && token[0] <= '9':
+		e.pos++
+		return parseLiteral(token, e.abi)
+	case isWordChar(token[0]):
+		e.pos++
+		variable, ok := e.variables[token]
+		if !ok {
+			return operand{}, fmt.Errorf("unknown variable '%s'", token)
+		}
+		return variable, nil
+	default:
+		return operand{}, fmt.Errorf("unexpected '%s'", token)
+	}
+}
+
+// Parses the words of a type name, e.g. "unsigned long" or "uint8_t"
+func (e *evaluator) parseType() (Type, error) {
+	words := []string{}
+	for isTypeKeyword(e.peek()) || (len(words) == 0 && e.isTypeStart(e.peek())) {
+		words = append(words, e.peek())
+		e.pos++
+	}
+	name := strings.Join(words, " ")
+	t, ok := e.abi.LookupType(name)
+	if !ok {
+		return Type{}, fmt.Errorf("unsupported type '%s'", name)
+	}
+	return t, nil
+}
+
+// Parses an operand with any number of prefix operators and casts
+func (e *evaluator) parseUnary() (operand, error) {
+	symbol := e.peek()
+	if symbol == "(" && e.pos+1 < len(e.tokens) && e.isTypeStart(e.tokens[e.pos+1]) {
+		e.pos++
+		t, err := e.parseType()
+		if err != nil {
+			return operand{}, err
+		}
+		if err := e.expect(")"); err != nil {
+			return operand{}, err
+		}
+		o, err := e.parseUnary()
+		if err != nil {
+			return operand{}, err
+		}
+		return e.convert(o, t, true), nil
+	}
+	if symbol != "-" && symbol != "+" && symbol != "~" {
+		return e.parsePrimary()
+	}
+	e.pos++
+	o, err := e.parseUnary()
+	if err != nil {
+		return operand{}, err
+	}
+	promoted := e.promote(o)
+	text := symbol + o.text
+	t := promoted.typ
+	switch symbol {
+	case "-":
+		x := big.NewInt(0).Neg(promoted.x)
+		behavior, note := e.arithmeticBehavior(x, t, text)
+		return e.step(operand{text, t, t.Wrap(x)}, behavior, note), nil
+	case "~":
+		return e.step(operand{text, t, t.Wrap(big.NewInt(0).Not(promoted.x))}, DEFINED, ""), nil
+	default:
+		return operand{text, t, promoted.x}, nil
+	}
+}
+
+// Returns the next token, or "" at the end of the statement
+func (e *evaluator) peek() string {
+	if e.pos >= len(e.tokens) {
+		return ""
+	}
+	return e.tokens[e.pos]
+}
+
+// Returns the operand after the integer promotions
+func (e *evaluator) promote(o operand) operand {
+	return e.convert(o, e.abi.Promote(o.typ), false)
+}
+
+// Evaluates one statement and returns its steps
+func (e *evaluator) run(statement string) ([]Step, error) {
+	tokens, err := tokenize(statement)
+	if err != nil {
+		return nil, err
+	}
+	e.tokens, e.pos, e.steps = tokens, 0, nil
+
+	// A declaration or an assignment converts the value to the type of the variable
+	name := ""
+	var target Type
+	switch {
+	case e.isTypeStart(e.peek()):
+		target, err = e.parseType()
+		if err != nil {
+			return nil, err
+		}
+		name = e.peek()
+		if name == "" || !isWordChar(name[0]) || ('0' <= name[0] && name[0] <= '9') || e.isTypeStart(name) {
+			return nil, fmt.Errorf("expected a variable name after '%s'", target.Name)
+		}
+		e.pos++
+		if err := e.expect("="); err != nil {
+			return nil, err
+		}
+	case len(tokens) > 1 && tokens[1] == "=":
+		name = tokens[0]
+		variable, ok := e.variables[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown variable '%s'", name)
+		}
+		target = variable.typ
+		e.pos += 2
+	}
+
+	result, err := e.parseExpression(1)
+	if err != nil {
+		return nil, err
+	}
+	if e.pos < len(e.tokens) {
+		return nil, fmt.Errorf("unexpected '%s'", e.peek())
+	}
+
+	if name != "" {
+		behavior, note, converted := conversionBehavior(result.x, target)
+		e.variables[name] = operand{name, target, converted}
+		e.step(operand{name + " = " + result.text, target, converted}, behavior, note)
+		return e.steps, nil
+	}
+	if len(e.steps) == 0 || e.steps[len(e.steps)-1].Expression != result.text {
+		e.step(result, DEFINED, "")
+	}
+	return e.steps, nil
+}
+
+// Shifts the promoted left operand by the promoted right operand. The result has the type of the left operand.
+// Shifting by a negative count or by the width or more is undefined, as is shifting a negative number left or
+// shifting a one into or past the sign bit. Shifting a negative number right is implementation-defined, and most
+// compilers shift in copies of the sign bit
+func (e *evaluator) shift(symbol string, left, right operand, text string) operand {
+	t := left.typ
+	count := right.x
+	if count.Sign() < 0 || count.Cmp(big.NewInt(int64(t.Bits))) >= 0 {
+		note := fmt.Sprintf("shift count %s is outside 0 to %d for %s", count, t.Bits-1, t.Name)
+		return e.step(operand{text, t, big.NewInt(0)}, UNDEFINED, note)
+	}
+	n := uint(count.Uint64())
+	behavior, note := DEFINED, ""
+	x := big.NewInt(0)
+	if symbol == ">>" {
+		x.Rsh(left.x, n)
+		if left.x.Sign() < 0 {
+			behavior, note = IMPLEMENTATION_DEFINED, fmt.Sprintf("right shift of negative %s %s, most compilers shift in the sign bit", t.Name, left.x)
+		}
+		return e.step(operand{text, t, x}, behavior, note)
+	}
+	x.Lsh(left.x, n)
+	switch {
+	case t.Signed && left.x.Sign() < 0:
+		behavior, note = UNDEFINED, fmt.Sprintf("left shift of negative %s %s", t.Name, left.x)
+	case t.Signed && !t.Contains(x):
+		behavior, note = UNDEFINED, fmt.Sprintf("%s is %s which does not fit in %s", text, x, t.Name)
+	case !t.Contains(x):
+		behavior, note = WRAPPED, fmt.Sprintf("bits shifted out, %s is %s which wraps around to %s in %s", text, x, t.Wrap(x), t.Name)
+	}
+	return e.step(operand{text, t, t.Wrap(x)}, behavior, note)
+}
+
+// Records the operand as a step and returns it
+func (e *evaluator) step(o operand, behavior Behavior, note string) operand {
+	e.steps = append(e.steps, Step{
+		Expression: o.text,
+		Type:       o.typ,
+		Value:      o.typ.value(o.x),
+		Behavior:   behavior,
+		Note:       note,
+	})
+	return o
+}
+
+// Returns the name of the behavior, or "" if it is well-defined
+func (b Behavior) String() string {
+	return [...]string{"", "wraps", "implementation-defined", "undefined"}[b]
+}
@@ -0,0 +1,120 @@
+package cint
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLiteralTypes(t *testing.T) {
+	var vector = []struct {
+		abi     string
+		literal string
+		want    string
+	}{
+		{"lp64", "0", "int"},
+		{"lp64", "2147483647", "int"},
+		{"lp64", "2147483648", "long"},
+		{"ilp32", "2147483648", "long long"},
+		{"lp64", "0x80000000", "unsigned int"},
+		{"lp64", "0xffffffffffffffff", "unsigned long"},
+		{"llp64", "0xffffffffffffffff", "unsigned long long"},
+		{"lp64", "017", "int"},
+		{"lp64", "1u", "unsigned int"},
+		{"lp64", "1l", "long"},
+		{"lp64", "1ULL", "unsigned long long"},
+		{"lp64", "1LLU", "unsigned long long"},
+	}
+	for _, tt := range vector {
+		abi, _ := LookupABI(tt.abi)
+		o, err := parseLiteral(tt.literal, abi)
+		want, _ := abi.LookupType(tt.want)
+		if err != nil || !o.typ.Is(want) {
+			t.Errorf("%s in %s: want %s, have %s (%v)", tt.literal, tt.abi, tt.want, o.typ.Name, err)
+		}
+	}
+	for _, literal := range []string{"08", "0o7", "1_000", "1lul", "1uu", "1lll", "1lL", "99999999999999999999"} {
+		if _, err := parseLiteral(literal, ABIS[0]); err == nil {
+			t.Errorf("Want an error for '%s'", literal)
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	var vector = []struct {
+		program  string
+		want     string
+		behavior Behavior
+	}{
+		{"uint8_t a = 0xf0; a << 4", "3840", DEFINED},
+		{"uint8_t a = 0xf0; int16_t b = -3; a + b", "237", DEFINED},
+		{"uint8_t a = 0xf0; int8_t c = a", "-16", IMPLEMENTATION_DEFINED},
+		{"(uint8_t)-1", "255", WRAPPED},
+		{"unsigned u = 1; u - 2", "4294967295", WRAPPED},
+		{"-1 < 1u", "0", DEFINED},
+		{"-1 < 1l", "1", DEFINED},
+		{"1 << 31", "-2147483648", UNDEFINED},
+		{"1u << 31", "2147483648", DEFINED},
+		{"1 << 32", "0", UNDEFINED},
+		{"-1 << 1", "-2", UNDEFINED},
+		{"-8 >> 1", "-4", IMPLEMENTATION_DEFINED},
+		{"2147483647 + 1", "-2147483648", UNDEFINED},
+		{"int m = -2147483647 - 1; m / -1", "-2147483648", UNDEFINED},
+		{"7 / 0", "0", UNDEFINED},
+		{"-7 / 2", "-3", DEFINED},
+		{"-7 % 2", "-1", DEFINED},
+		{"~0u", "4294967295", DEFINED},
+		{"6 & 3 | 8 ^ 1", "11", DEFINED},
+		{"1 + 2 * 3 << 1", "14", DEFINED},
+		{"short s = 1; s = s + 40000", "-25535", IMPLEMENTATION_DEFINED},
+	}
+	for _, tt := range vector {
+		statements, err := Run(tt.program, ABIS[0])
+		if err != nil {
+			t.Errorf("%s: %v", tt.program, err)
+			continue
+		}
+		steps := statements[len(statements)-1].Steps
+		last := steps[len(steps)-1]
+		if last.Value.Dec() != tt.want || last.Behavior != tt.behavior {
+			t.Errorf("%s: want %s (%s), have %s (%s)", tt.program, tt.want, tt.behavior, last.Value.Dec(), last.Behavior)
+		}
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	var vector = []struct {
+		program string
+		want    string
+	}{
+		{"a + 1", "unknown variable 'a'"},
+		{"int = 3", "expected a variable name"},
+		{"char c = 1", "unsupported type 'char'"},
+		{"(1 + 2", "expected ')' at the end"},
+		{"1 +", "unexpected end"},
+		{"1 2", "unexpected '2'"},
+		{"1.5", "unexpected character '.'"},
+	}
+	for _, tt := range vector {
+		_, err := Run(tt.program, ABIS[0])
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: want an error with '%s', have %v", tt.program, tt.want, err)
+		}
+	}
+}
+
+func TestRunSteps(t *testing.T) {
+	statements, err := Run("uint8_t a = 0xf0; int16_t b = -3; a + b", ABIS[0])
+	if err != nil || len(statements) != 3 {
+		t.Fatalf("Want 3 statements, have %d (%v)", len(statements), err)
+	}
+	want := []string{"(int)a int 240", "(int)b int -3", "a + b int 237"}
+	steps := statements[2].Steps
+	if len(steps) != len(want) {
+		t.Fatalf("Want %d steps, have %+v", len(want), steps)
+	}
+	for i, step := range steps {
+		if have := step.Expression + " " + step.Type.Name + " " + step.Value.Dec(); have != want[i] {
+			t.Errorf("Step %d: want %s, have %s", i, want[i], have)
+		}
+	}
+}
@@ -0,0 +1,217 @@
+package cint
+
+import (
+	"github.com/jonathangjertsen/jco-go/jco"
+	"math/big"
+	"strings"
+)
+
+// Integer conversion ranks of the standard integer types, lowest first
+const (
+	RANK_CHAR = iota + 1
+	RANK_SHORT
+	RANK_INT
+	RANK_LONG
+	RANK_LONG_LONG
+)
+
+// An integer type of C, with its width in a data model
+type Type struct {
+	// Name as it was written, e.g. "uint8_t" or "unsigned long"
+	Name string
+
+	Rank   int
+	Signed bool
+	Bits   uint
+}
+
+// Names of the standard integer types by rank, unsigned first
+var RANK_NAMES = map[int][2]string{
+	RANK_CHAR:      {"unsigned char", "signed char"},
+	RANK_SHORT:     {"unsigned short", "short"},
+	RANK_INT:       {"unsigned int", "int"},
+	RANK_LONG:      {"unsigned long", "long"},
+	RANK_LONG_LONG: {"unsigned long long", "long long"},
+}
+
+// Words that can make up the name of a standard integer type
+var TYPE_KEYWORDS = []string{"signed", "unsigned", "char", "short", "int", "long"}
+
+// Returns whether the word is one of the keywords in the names of the standard integer types
+func isTypeKeyword(word string) bool {
+	for _, keyword := range TYPE_KEYWORDS {
+		if word == keyword {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the standard integer type with the rank and signedness
+func (abi ABI) basic(rank int, signed bool) Type {
+	bits := map[int]uint{
+		RANK_CHAR:      8,
+		RANK_SHORT:     abi.Short,
+		RANK_INT:       abi.Int,
+		RANK_LONG:      abi.Long,
+		RANK_LONG_LONG: abi.LongLong,
+	}[rank]
+	names := RANK_NAMES[rank]
+	name := names[0]
+	if signed {
+		name = names[1]
+	}
+	return Type{Name: name, Rank: rank, Signed: signed, Bits: bits}
+}
+
+// Returns the lowest rank with the given width
+func (abi ABI) rankWithBits(bits uint) int {
+	for rank := RANK_CHAR; rank <= RANK_LONG_LONG; rank++ {
+		if abi.basic(rank, true).Bits == bits {
+			return rank
+		}
+	}
+	return RANK_LONG_LONG
+}
+
+// Returns the type with the given name, which is a standard integer type like "unsigned long" or "short int", or
+// one of the types from stdint.h and stddef.h like "uint8_t" or "size_t". Plain char is not supported, since
+// whether it is signed is up to the compiler
+func (abi ABI) LookupType(name string) (Type, bool) {
+	name = strings.Join(strings.Fields(name), " ")
+	pointerRank := abi.rankWithBits(abi.Pointer)
+	typedefs := map[string]Type{
+		"int8_t":    abi.basic(RANK_CHAR, true),
+		"uint8_t":   abi.basic(RANK_CHAR, false),
+		"int16_t":   abi.basic(abi.rankWithBits(16), true),
+		"uint16_t":  abi.basic(abi.rankWithBits(16), false),
+		"int32_t":   abi.basic(abi.rankWithBits(32), true),
+		"uint32_t":  abi.basic(abi.rankWithBits(32), false),
+		"int64_t":   abi.basic(abi.rankWithBits(64), true),
+		"uint64_t":  abi.basic(abi.rankWithBits(64), false),
+		"intmax_t":  abi.basic(abi.rankWithBits(64), true),
+		"uintmax_t": abi.basic(abi.rankWithBits(64), false),
+		"size_t":    abi.basic(pointerRank, false),
+		"ptrdiff_t": abi.basic(pointerRank, true),
+		"intptr_t":  abi.basic(pointerRank, true),
+		"uintptr_t": abi.basic(pointerRank, false),
+	}
+	if t, ok := typedefs[name]; ok {
+		t.Name = name
+		return t, true
+	}
+
+	// Count the keywords, which may come in any order
+	if name == "" {
+		return Type{}, false
+	}
+	count := map[string]int{}
+	for _, word := range strings.Fields(name) {
+		if !isTypeKeyword(word) {
+			return Type{}, false
+		}
+		count[word]++
+	}
+	signed := count["unsigned"] == 0
+	rank := RANK_INT
+	switch {
+	case count["signed"]+count["unsigned"] > 1 || count["int"] > 1 || count["char"] > 1 || count["short"] > 1:
+		return Type{}, false
+	case count["char"] == 1 && count["short"]+count["long"]+count["int"] == 0 && count["signed"]+count["unsigned"] == 1:
+		rank = RANK_CHAR
+	case count["char"] == 1:
+		return Type{}, false
+	case count["short"] == 1 && count["long"] == 0:
+		rank = RANK_SHORT
+	case count["short"] == 0 && count["long"] == 1:
+		rank = RANK_LONG
+	case count["short"] == 0 && count["long"] == 2:
+		rank = RANK_LONG_LONG
+	case count["short"] > 0 || count["long"] > 0:
+		return Type{}, false
+	}
+	t := abi.basic(rank, signed)
+	t.Name = name
+	return t, true
+}
+
+// Returns the type after the integer promotions: types with a lower rank than int become int
+func (abi ABI) Promote(t Type) Type {
+	if t.Rank < RANK_INT {
+		return abi.basic(RANK_INT, true)
+	}
+	return t
+}
+
+// Returns the type that both operands are converted to by the usual arithmetic conversions
+func (abi ABI) UsualArithmeticConversion(a, b Type) Type {
+	a, b = abi.Promote(a), abi.Promote(b)
+	if a.Signed == b.Signed {
+		if b.Rank > a.Rank {
+			return b
+		}
+		return a
+	}
+	unsigned, signed := a, b
+	if a.Signed {
+		unsigned, signed = b, a
+	}
+	switch {
+	case unsigned.Rank >= signed.Rank:
+		return unsigned
+	case signed.Bits > unsigned.Bits:
+		return signed
+	default:
+		return abi.basic(signed.Rank, false)
+	}
+}
+
+// Returns the value of the type with the number, which must be in range
+func (t Type) value(x *big.Int) jco.Value {
+	modulus := big.NewInt(0).Lsh(big.NewInt(1), t.Bits)
+	bytes := big.NewInt(0).Mod(x, modulus).FillBytes(make([]byte, t.Bits/8))
+	value, _ := jco.New(bytes, t.Bits)
+	if t.Signed {
+		return value.AsSigned()
+	}
+	return value
+}
+
+// Returns whether the type can hold the number
+func (t Type) Contains(x *big.Int) bool {
+	return x.Cmp(t.Min()) >= 0 && x.Cmp(t.Max()) <= 0
+}
+
+// Returns whether the types are the same, even if they are written differently (e.g. uint32_t and unsigned int)
+func (t Type) Is(other Type) bool {
+	return t.Rank == other.Rank && t.Signed == other.Signed && t.Bits == other.Bits
+}
+
+// Returns the largest number of the type
+func (t Type) Max() *big.Int {
+	bits := t.Bits
+	if t.Signed {
+		bits--
+	}
+	max := big.NewInt(0).Lsh(big.NewInt(1), bits)
+	return max.Sub(max, big.NewInt(1))
+}
+
+// Returns the smallest number of the type
+func (t Type) Min() *big.Int {
+	if !t.Signed {
+		return big.NewInt(0)
+	}
+	return big.NewInt(0).Neg(big.NewInt(0).Lsh(big.NewInt(1), t.Bits-1))
+}
+
+// Returns the number of the type which is congruent to x modulo 2^Bits, which is what converting x to an unsigned
+// type gives, and what converting it to a signed type gives on two's complement machines
+func (t Type) Wrap(x *big.Int) *big.Int {
+	modulus := big.NewInt(0).Lsh(big.NewInt(1), t.Bits)
+	wrapped := big.NewInt(0).Mod(x, modulus)
+	if wrapped.Cmp(t.Max()) > 0 {
+		wrapped.Sub(wrapped, modulus)
+	}
+	return wrapped
+}
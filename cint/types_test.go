@@ -0,0 +1,67 @@
+package cint
+
+import (
+	"testing"
+)
+
+func TestLookupType(t *testing.T) {
+	var vector = []struct {
+		abi    string
+		name   string
+		bits   uint
+		signed bool
+		rank   int
+	}{
+		{"lp64", "uint8_t", 8, false, RANK_CHAR},
+		{"lp64", "signed char", 8, true, RANK_CHAR},
+		{"lp64", "short int", 16, true, RANK_SHORT},
+		{"lp64", "unsigned", 32, false, RANK_INT},
+		{"lp64", "long", 64, true, RANK_LONG},
+		{"llp64", "long", 32, true, RANK_LONG},
+		{"lp64", "int long  unsigned", 64, false, RANK_LONG},
+		{"lp64", "long long int", 64, true, RANK_LONG_LONG},
+		{"lp64", "int64_t", 64, true, RANK_LONG},
+		{"llp64", "int64_t", 64, true, RANK_LONG_LONG},
+		{"ilp32", "int32_t", 32, true, RANK_INT},
+		{"ilp32", "size_t", 32, false, RANK_INT},
+		{"llp64", "size_t", 64, false, RANK_LONG_LONG},
+	}
+	for _, tt := range vector {
+		abi, _ := LookupABI(tt.abi)
+		typ, ok := abi.LookupType(tt.name)
+		if !ok || typ.Bits != tt.bits || typ.Signed != tt.signed || typ.Rank != tt.rank {
+			t.Errorf("%s in %s: want %d bits signed=%v rank %d, have %+v (ok=%v)", tt.name, tt.abi, tt.bits, tt.signed, tt.rank, typ, ok)
+		}
+	}
+	for _, name := range []string{"", "char", "float", "long short", "signed unsigned", "long long long", "int int", "uint8"} {
+		if typ, ok := ABIS[0].LookupType(name); ok {
+			t.Errorf("Want no type for '%s', have %+v", name, typ)
+		}
+	}
+}
+
+func TestUsualArithmeticConversion(t *testing.T) {
+	var vector = []struct {
+		abi  string
+		a    string
+		b    string
+		want string
+	}{
+		{"lp64", "uint8_t", "int16_t", "int"},
+		{"lp64", "unsigned short", "unsigned char", "int"},
+		{"lp64", "unsigned int", "int", "unsigned int"},
+		{"lp64", "unsigned int", "long", "long"},
+		{"llp64", "unsigned int", "long", "unsigned long"},
+		{"lp64", "unsigned long", "long long", "unsigned long long"},
+		{"ilp32", "unsigned long", "long long", "long long"},
+	}
+	for _, tt := range vector {
+		abi, _ := LookupABI(tt.abi)
+		a, _ := abi.LookupType(tt.a)
+		b, _ := abi.LookupType(tt.b)
+		want, _ := abi.LookupType(tt.want)
+		if have := abi.UsualArithmeticConversion(a, b); !have.Is(want) {
+			t.Errorf("%s and %s in %s: want %s, have %s", tt.a, tt.b, tt.abi, tt.want, have.Name)
+		}
+	}
+}
@@ -0,0 +1,222 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/jonathangjertsen/jco-go/expr"
+	"github.com/jonathangjertsen/jco-go/jco"
+	"github.com/jonathangjertsen/jco-go/ops"
+	"github.com/jonathangjertsen/jco-go/table"
+	"os"
+	"strings"
+)
+
+const (
+	// Number of lines that may be read ahead of the line that is currently being written, per worker
+	BATCH_LINES_IN_FLIGHT_PER_JOB = 16
+
+	// Longest line that can be read in batch mode
+	BATCH_MAX_LINE_LENGTH = 1024 * 1024
+)
+
+// A line of batch input, and where to deliver the output once it has been evaluated
+type batchJob struct {
+	lineNumber int
+	line       string
+	result     chan batchResult
+}
+
+// The result of evaluating one line of batch input, as written with --format json
+type batchRecord struct {
+	Line  int                 `json:"line"`
+	Input string              `json:"input"`
+	Rows  []map[string]string `json:"rows"`
+	Error string              `json:"error,omitempty"`
+}
+
+// Formatted output for one line of batch input
+type batchResult struct {
+	output string
+	err    error
+}
+
+// Evaluates one line of batch input, which is either one or two numbers or an expression
+func evaluateLine(line string, flags *Flags) (*table.Table, error) {
+	t := newTable(flags)
+	fields := strings.Fields(line)
+	numbers := []jco.Value{}
+	for _, field := range fields {
+		number, err := flags.parseNumber(field)
+		if errors.Is(err, ops.ErrInvalidNumber) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		numbers = append(numbers, number)
+	}
+	switch {
+	case len(numbers) == 1 && len(fields) == 1:
+		t.One(numbers[0], fields[0])
+	case len(numbers) == 2 && len(fields) == 2:
+		t.Two(numbers[0], numbers[1], fields[0], fields[1])
+	default:
+		result, err := expr.Evaluate(line, flags.bits/8)
+		if err != nil {
+			return nil, err
+		}
+		value, err := jco.New(result, flags.bits)
+		if err != nil {
+			return nil, err
+		}
+		t.One(value, "("+line+")")
+	}
+	if flags.register != nil && len(numbers) == 1 && len(fields) == 1 {
+		t.Fields(numbers[0], flags.register)
+	}
+	return t, nil
+}
+
+// Evaluates one line of batch input and formats it according to --format
+func formatLine(lineNumber int, line string, flags *Flags) batchResult {
+	t, err := evaluateLine(line, flags)
+	return formatTable(lineNumber, line, t, err, flags)
+}
+
+// Formats the table that resulted from one line of input according to --format
+func formatTable(lineNumber int, line string, t *table.Table, err error, flags *Flags) batchResult {
+	if err != nil {
+		err = fmt.Errorf("line %d: %v", lineNumber, err)
+	}
+
+	switch flags.outputFormat {
+	case "json":
+		record := batchRecord{Line: lineNumber, Input: line}
+		if err != nil {
+			record.Error = err.Error()
+		} else {
+			record.Rows = tableRows(t)
+		}
+		encoded, _ := json.Marshal(record)
+		return batchResult{string(encoded) + "\n", err}
+	case "csv":
+		if err != nil {
+			return batchResult{"", err}
+		}
+		var buffer bytes.Buffer
+		csvWriter := csv.NewWriter(&buffer)
+		for _, cells := range t.Cells() {
+			for i := range cells {
+				cells[i] = strings.TrimSpace(cells[i])
+			}
+			csvWriter.Write(append([]string{line}, cells...))
+		}
+		csvWriter.Flush()
+		return batchResult{buffer.String(), nil}
+	default:
+		if err != nil {
+			return batchResult{"", err}
+		}
+		return batchResult{t.String() + "\n", nil}
+	}
+}
+
+// Returns the cells of the table keyed by column name
+func tableRows(t *table.Table) []map[string]string {
+	rows := []map[string]string{}
+	columns := t.Columns()
+	for _, cells := range t.Cells() {
+		row := map[string]string{}
+		for i, cell := range cells {
+			row[columns[i]] = strings.TrimSpace(cell)
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// Evaluates every line of the file (or stdin if the path is "-") and prints one result for each of them.
+// Empty lines and lines starting with # are skipped. Lines that can not be evaluated are reported without
+// stopping the batch, and cause an error to be returned at the end.
+//
+// The lines are evaluated by flags.jobs workers, and the results are written in input order as soon as they
+// are ready, so only a bounded number of lines is held in memory no matter how long the input is
+func RunBatch(path string, flags *Flags) error {
+	input := flags.streams.In
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		input = file
+	}
+
+	// Workers evaluate lines in any order
+	jobs := make(chan batchJob)
+	for i := uint(0); i < flags.jobs; i++ {
+		go func() {
+			for job := range jobs {
+				job.result <- formatLine(job.lineNumber, job.line, flags)
+			}
+		}()
+	}
+
+	// The reader hands out lines to the workers, and queues them up for the writer in input order
+	pending := make(chan batchJob, BATCH_LINES_IN_FLIGHT_PER_JOB*flags.jobs)
+	var scanErr error
+	go func() {
+		defer close(pending)
+		defer close(jobs)
+		scanner := bufio.NewScanner(input)
+		scanner.Buffer(nil, BATCH_MAX_LINE_LENGTH)
+		for lineNumber := 1; scanner.Scan(); lineNumber++ {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			job := batchJob{lineNumber, line, make(chan batchResult, 1)}
+			pending <- job
+			jobs <- job
+		}
+		scanErr = scanner.Err()
+	}()
+
+	// The writer waits for each line in turn
+	output := bufio.NewWriter(flags.streams.Out)
+	if flags.outputFormat == "csv" {
+		csvWriter := csv.NewWriter(output)
+		csvWriter.Write(append([]string{"input"}, newTable(flags).Columns()...))
+		csvWriter.Flush()
+	}
+	nFailed := 0
+	for job := range pending {
+		result := <-job.result
+		output.WriteString(result.output)
+		if result.err != nil {
+			nFailed++
+			if flags.outputFormat != "json" {
+				output.Flush()
+				fmt.Fprintf(flags.streams.Err, "jco: %v\n", result.err)
+			}
+		}
+
+		// Flush whenever we have caught up with the input, so that interactive use shows results immediately
+		if len(pending) == 0 {
+			output.Flush()
+		}
+	}
+	output.Flush()
+
+	if scanErr != nil {
+		return scanErr
+	}
+	if nFailed > 0 {
+		return fmt.Errorf("%d lines could not be evaluated", nFailed)
+	}
+	return nil
+}
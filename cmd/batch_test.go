@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestRunBatchOrder(t *testing.T) {
+	isolateConfig(t)
+
+	// Lines that take different amounts of work, so that the workers finish them out of order
+	var input strings.Builder
+	for i := 0; i < 500; i++ {
+		switch i % 5 {
+		case 0:
+			fmt.Fprintf(&input, "%d\n", i)
+		case 1:
+			fmt.Fprintf(&input, "0x%x 0x%x\n", i, 3*i)
+		case 2:
+			fmt.Fprintf(&input, "(%d + %d) << 2 | popcount(%d)\n", i, i, i)
+		case 3:
+			fmt.Fprintf(&input, "bad%d\n", i)
+		case 4:
+			fmt.Fprintf(&input, "# comment %d\n", i)
+		}
+	}
+
+	for _, format := range []string{"table", "json", "csv"} {
+		t.Run(format, func(t *testing.T) {
+			outputs := map[string]string{}
+			for _, jobs := range []string{"1", "8"} {
+				var stdout, stderr bytes.Buffer
+				args := []string{"--batch", "-", "-b", "16", "--format", format, "--jobs", jobs}
+				err := run(args, Streams{strings.NewReader(input.String()), &stdout, &stderr})
+				outputs[jobs] = fmt.Sprintf("%s%s%v", stdout.String(), stderr.String(), err)
+			}
+			want := strings.Split(outputs["1"], "\n")
+			have := strings.Split(outputs["8"], "\n")
+			if len(have) != len(want) {
+				t.Fatalf("Want %d lines with --jobs 8, have %d", len(want), len(have))
+			}
+			for i := range want {
+				if have[i] != want[i] {
+					t.Fatalf("Line %d differs with --jobs 8\nwant %q\nhave %q", i+1, want[i], have[i])
+				}
+			}
+		})
+	}
+}
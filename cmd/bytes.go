@@ -0,0 +1,23 @@
+package cmd
+
+import (
+	"github.com/jonathangjertsen/jco-go/table"
+)
+
+// Shows each byte of the numbers on its own row, numbered from the least significant byte with --little-index
+func RunBytes(flags *Flags) error {
+	if flags.batch != "" || flags.follow || flags.register != nil || len(flags.widths) > 0 {
+		return usageError("--bytes can not be combined with --batch, --follow, --register or --widths")
+	}
+	if len(flags.numbers) == 0 {
+		return usageError("expected at least 1 number")
+	}
+	t := table.NewBytes()
+	t.SetFormat(flags.format)
+	t.SetColumns(flags.columns)
+	t.SetLittleEndian(flags.littleIndex)
+	for i, number := range flags.numbers {
+		t.One(number, flags.numbersAsWritten[i])
+	}
+	return t.RenderTo(flags.streams.Out)
+}
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/jonathangjertsen/jco-go/cint"
+	"github.com/jonathangjertsen/jco-go/table"
+	"io"
+	"strings"
+)
+
+// Evaluates a C program made of integer declarations and expressions with the data model in --abi, and shows the
+// type and value after each promotion, conversion and operation. The program is read from stdin if it is -
+func RunC(args []string, streams Streams) error {
+	flags, positional, err := parseOptions(args, streams)
+	if err != nil {
+		return err
+	}
+	if flags.help {
+		Usage(streams.Out)
+		return nil
+	}
+	program := strings.Join(positional, "; ")
+	if program == "-" {
+		input, err := io.ReadAll(streams.In)
+		if err != nil {
+			return err
+		}
+		program = string(input)
+	}
+	if strings.TrimSpace(program) == "" {
+		return usageError("expected a C program, e.g. jco c 'uint8_t a = 0xf0; a << 4'")
+	}
+	statements, err := cint.Run(program, flags.abi)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(streams.Out, "%s\n", flags.abi)
+	for _, statement := range statements {
+		grid := [][]string{{"EXPRESSION", "TYPE", "DECIMAL", "HEXADECIMAL", "BEHAVIOR"}}
+		notes := []string{}
+		for _, step := range statement.Steps {
+			grid = append(grid, []string{
+				step.Expression,
+				step.Type.Name,
+				step.Value.DecGrouped(flags.format.GroupDec, flags.format.Separator),
+				step.Value.HexGrouped(flags.format.GroupHex, flags.format.Separator),
+				step.Behavior.String(),
+			})
+			if step.Behavior != cint.DEFINED {
+				notes = append(notes, fmt.Sprintf("%s: %s", step.Behavior, step.Note))
+			}
+		}
+		fmt.Fprintf(streams.Out, "\n%s\n%s", statement.Text, table.RenderGrid(grid, 1, flags.format.Color))
+		for _, note := range notes {
+			fmt.Fprintf(streams.Out, "  %s\n", note)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata with the current output")
+
+// Runs the rest of the test in an empty directory, with the home and config directories pointing there too, so that
+// neither the user's config file nor a .jco file above the package can change the output
+func isolateConfig(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("AppData", dir)
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(cwd) })
+}
+
+// Runs jco with the arguments and input, and returns everything it printed along with the exit status
+func runGolden(args []string, input string) string {
+	var stdout, stderr bytes.Buffer
+	err := run(args, Streams{strings.NewReader(input), &stdout, &stderr})
+	if err != nil {
+		fmt.Fprintf(&stderr, "jco: %v\n", err)
+	}
+	return fmt.Sprintf("%s--- stderr\n%s--- exit status %d\n", stdout.String(), stderr.String(), ExitCode(err))
+}
+
+func TestGolden(t *testing.T) {
+	var vector = []struct {
+		name  string
+		args  string
+		input string
+	}{
+		{"one", "0x1877", ""},
+		{"one_grouped_ruler", "0x1877 --group --ruler -b 16", ""},
+		{"one_negative", "-5 -b 8", ""},
+		{"one_little_endian", "0x1234 --endian little -b 16", ""},
+		{"one_selection", "0x1877 --ops popcount,clz,value --columns formula,hex", ""},
+		{"one_register", "0x1877 -b 16 --register EN[0],MODE[3:1],DIV[15:8]", ""},
+		{"two", "0x12 0x34 -b 8", ""},
+		{"two_truncated", "0xffffffff 0xffffffff", ""},
+		{"auto_width", "0x12:8 0x00ff --auto-width --ops a,b,add", ""},
+		{"two_types", "0x12:u8 0xfff0:i16 --ops a,b,add --promote c", ""},
+		{"two_types_widest", "0x12:u8 0xfff0:i16 --ops a,b,add", ""},
+		{"signed_overflow", "0x7fff:i16 1:i16 --ops add", ""},
+		{"one_typed_register", "0x1877:u16 --ops value --register EN[0],MODE[3:1],DIV[15:8]", ""},
+		{"error_typed_register", "0x12:8 --register DIV[15:8]", ""},
+		{"widths", "0xfff3 --widths 8,16,32 --ops value,not,twos_complement,clz,reverse_byteorder --columns formula,dec,hex", ""},
+		{"c_promotion", "c -", "uint8_t a = 0xf0; int16_t b = -3\na << 4\na + b\nint8_t c = a + b\n1 << 31\n"},
+		{"c_ilp32", "c - --abi ilp32", "long x = 1; x << 40; size_t n = 3; n - 4\n"},
+		{"bytes", "0x4a434f0a 0x1234 --bytes --group", ""},
+		{"bytes_little_index", "0x4a434f0a --bytes --little-index --columns formula,hex", ""},
+		{"text", "U+1F600 \"J\\tC\\xe9\" --text", ""},
+		{"text_literals", "'A' 'a' -b 8 --ops a,b,xor", ""},
+		{"text_character", "'é' -b 16 --text", ""},
+		{"text_colons", "':' \"a:b\":u32 --ops a,b", ""},
+		{"in_base", "2rz 11111112rz --in-base base58 -b 64 --ops a,b,xor --columns formula,hex,base58", ""},
+		{"out_base", "0x1877 --out-base 36 --ops value,not --columns formula,dec,base,crockford32,base64url", ""},
+		{"error_in_base", "9 --in-base 8", ""},
+		{"alphabet", "0x1877 --alphabet 0123456789ABCDEF --ops value,not --columns formula,dec,base", ""},
+		{"in_base_alphabet", "ZY YX --in-base 3 --alphabet XYZ -b 8 --ops a,b,add --columns formula,dec,base", ""},
+		{"error_alphabet", "5 --alphabet 01 --out-base 16", ""},
+		{"bcd", "0x2359 -b 16 --ops value,bcd,from_bcd,from_unpacked_bcd", ""},
+		{"bcd_invalid", "0x1a09 -b 16 --ops from_bcd,from_unpacked_bcd", ""},
+		{"bcd_arithmetic", "0x0199 0x0001 -b 16 --ops bcd_add,bcd_sub", ""},
+		{"operation", "add 0xff 1 --as hex -b 8", ""},
+		{"operation_invalid", "from_bcd 0x1a -b 8", ""},
+		{"widths_invalid", "0x1a09 --widths 16,32 --ops from_bcd --columns formula,hex", ""},
+		{"batch_table", "--batch - -b 16", "0x12 0x34\n# comment\n\n1 + 2 << 3\nbad\n"},
+		{"batch_json", "--batch - -b 8 --format json --ops value,popcount", "0x12\n0x1ff\n"},
+		{"batch_csv", "--batch - -b 8 --format csv --columns formula,hex", "0x12\npopcount(7)\n"},
+		{"follow_changes", "--follow --changes -b 8 --format csv --ops value --columns hex", "REG=0x01 0x02\nREG=0x01 0x03\n"},
+		{"error_invalid_number", "popcnt 5", ""},
+		{"error_overflow", "0x1ff -b 8", ""},
+		{"error_unknown_option", "0x12 --bitz 8", ""},
+		{"error_ops_arity", "5 --ops add,sub", ""},
+		{"version", "--version", ""},
+	}
+
+	golden, err := filepath.Abs("testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	isolateConfig(t)
+
+	for _, tt := range vector {
+		t.Run(tt.name, func(t *testing.T) {
+			have := runGolden(strings.Fields(tt.args), tt.input)
+			path := filepath.Join(golden, tt.name+".golden")
+			if *update {
+				if err := os.WriteFile(path, []byte(have), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("%v (run go test ./cmd -update to create it)", err)
+			}
+			if have != string(want) {
+				t.Errorf("Output of jco %s differs from %s\nWant:\n%s\nHave:\n%s", tt.args, path, want, have)
+			}
+		})
+	}
+}
+
+func TestNoArguments(t *testing.T) {
+	isolateConfig(t)
+	var stdout bytes.Buffer
+	err := run([]string{}, Streams{strings.NewReader(""), &stdout, &bytes.Buffer{}})
+	if ExitCode(err) != 0 || !strings.Contains(stdout.String(), "Usage:") {
+		t.Errorf("Want the usage and exit status 0, have exit status %d and\n%s", ExitCode(err), stdout.String())
+	}
+}
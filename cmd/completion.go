@@ -0,0 +1,278 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/jonathangjertsen/jco-go/ops"
+	"io"
+	"strings"
+	"text/template"
+)
+
+// Shells that jco can print completion scripts for
+var SHELLS = []string{"bash", "zsh", "fish"}
+
+const BASH_COMPLETION = `# bash completion for jco, generated by jco completion bash
+
+# Completes the last item of a comma-separated list, where each item may be prefixed with -
+_jco_list() {
+    local prefix="" word="$cur"
+    if [[ "$word" == *,* ]]; then
+        prefix="${word%,*},"
+        word="${word##*,}"
+    fi
+    if [[ "$word" == -* ]]; then
+        prefix="$prefix-"
+        word="${word#-}"
+    fi
+    COMPREPLY=($(compgen -P "$prefix" -W "$1" -- "$word"))
+    compopt -o nospace
+}
+
+_jco() {
+    local cur="${COMP_WORDS[COMP_CWORD]}"
+    local prev="${COMP_WORDS[COMP_CWORD-1]}"
+    if [[ $COMP_CWORD -eq 2 && "$prev" == completion ]]; then
+        COMPREPLY=($(compgen -W "{{.Shells}}" -- "$cur"))
+        return
+    fi
+    case "$prev" in
+{{range .Cases}}        {{.Pattern}})
+{{if .Action}}            {{.Action}}
+{{end}}            return
+            ;;
+{{end}}    esac
+    if [[ "$cur" == -* ]]; then
+        COMPREPLY=($(compgen -W "{{.Options}}" -- "$cur"))
+    elif [[ $COMP_CWORD -eq 1 ]]; then
+        COMPREPLY=($(compgen -W "{{.Commands}}" -- "$cur"))
+    fi
+}
+
+complete -F _jco jco
+`
+
+const FISH_COMPLETION = `# fish completion for jco, generated by jco completion fish
+
+complete -c jco -f
+complete -c jco -n '__fish_seen_subcommand_from completion' -a '{{.Shells}}'
+{{range .Lines}}{{.}}
+{{end}}`
+
+const ZSH_COMPLETION = `#compdef jco
+# zsh completion for jco, generated by jco completion zsh
+
+_jco() {
+    local -a commands
+    commands=(
+{{range .Commands}}        {{.}}
+{{end}}    )
+    if [[ $words[2] == completion ]]; then
+        _arguments '1:command:(completion)' '2:shell:({{.Shells}})'
+        return
+    fi
+    local state
+    _arguments -s -S \
+{{range .Specs}}        {{.}} \
+{{end}}        '1: :->command' \
+        '*:number:' && return
+    case $state in
+        command)
+            _describe 'command' commands
+            ;;
+    esac
+}
+
+if [[ "$funcstack[1]" == _jco ]]; then
+    _jco "$@"
+else
+    compdef _jco jco
+fi
+`
+
+// A branch in the bash completion function, completing the value of an option
+type bashCase struct {
+	Pattern string
+	Action  string
+}
+
+// Returns the bash completion script
+func bashCompletion() string {
+	cases := []bashCase{}
+	for _, option := range OPTIONS {
+		if option.Metavar == "" {
+			continue
+		}
+		action := ""
+		switch {
+		case option.Values != nil && option.Metavar == "NAMES":
+			action = fmt.Sprintf("_jco_list %q", strings.Join(option.Values(), " "))
+		case option.Values != nil:
+			action = fmt.Sprintf("COMPREPLY=($(compgen -W %q -- \"$cur\"))", strings.Join(option.Values(), " "))
+		case option.Metavar == "FILE":
+			action = "COMPREPLY=($(compgen -f -- \"$cur\"))"
+		}
+		cases = append(cases, bashCase{strings.Join(optionForms(option), "|"), action})
+	}
+	return completionScript(BASH_COMPLETION, map[string]interface{}{
+		"Shells":   strings.Join(SHELLS, " "),
+		"Cases":    cases,
+		"Options":  strings.Join(optionWords(), " "),
+		"Commands": strings.Join(commandNames(), " "),
+	})
+}
+
+// Returns the descriptions of the commands and operations which can be given as the first argument, by name
+func commandDescriptions() map[string]string {
+	descriptions := map[string]string{}
+	for _, command := range COMMANDS {
+		descriptions[command.Name] = command.Description
+	}
+	for _, arity := range []int{1, 2} {
+		for _, op := range ops.Operations(arity) {
+			if _, ok := descriptions[op.Name]; !ok {
+				descriptions[op.Name] = strings.Split(op.Description, "\n")[0]
+			}
+		}
+	}
+	return descriptions
+}
+
+// Returns the names of the commands and operations which can be given as the first argument
+func commandNames() []string {
+	names := []string{}
+	for _, command := range COMMANDS {
+		names = append(names, command.Name)
+	}
+	return append(names, operationNames()...)
+}
+
+// Fills in the completion script template
+func completionScript(text string, data interface{}) string {
+	var builder strings.Builder
+	template.Must(template.New("completion").Parse(text)).Execute(&builder, data)
+	return builder.String()
+}
+
+// Returns the fish completion script
+func fishCompletion() string {
+	lines := []string{}
+	descriptions := commandDescriptions()
+	for _, name := range commandNames() {
+		lines = append(lines, fmt.Sprintf("complete -c jco -n __fish_use_subcommand -a %s -d %s", name, fishQuote(descriptions[name])))
+	}
+	for _, option := range OPTIONS {
+		line := "complete -c jco"
+		if option.Short != 0 {
+			line += fmt.Sprintf(" -s %c", option.Short)
+		}
+		line += " -l " + option.Long
+		switch {
+		case option.Values != nil && option.Metavar == "NAMES":
+			list := fmt.Sprintf("(__fish_complete_list , 'printf \"%%s\\n\" %s')", strings.Join(option.Values(), " "))
+			line += " -x -a " + fishQuote(list)
+		case option.Values != nil:
+			line += " -x -a " + fishQuote(strings.Join(option.Values(), " "))
+		case option.Metavar == "FILE":
+			line += " -r -F"
+		case option.Metavar != "":
+			line += " -x"
+		}
+		lines = append(lines, line+" -d "+fishQuote(option.Description))
+	}
+	return completionScript(FISH_COMPLETION, map[string]interface{}{
+		"Shells": strings.Join(SHELLS, " "),
+		"Lines":  lines,
+	})
+}
+
+// Quotes the text as a single-quoted fish string
+func fishQuote(text string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(text) + "'"
+}
+
+// Returns the ways the option can be written, e.g. -b and --bits
+func optionForms(option Option) []string {
+	if option.Short != 0 {
+		return []string{fmt.Sprintf("-%c", option.Short), option.Name()}
+	}
+	return []string{option.Name()}
+}
+
+// Returns every way any option can be written
+func optionWords() []string {
+	words := []string{}
+	for _, option := range OPTIONS {
+		words = append(words, optionForms(option)...)
+	}
+	return words
+}
+
+// Quotes the text as a single-quoted zsh string
+func shellQuote(text string) string {
+	return "'" + strings.ReplaceAll(text, "'", `'\''`) + "'"
+}
+
+// Returns the zsh completion script
+func zshCompletion() string {
+	commands := []string{}
+	descriptions := commandDescriptions()
+	for _, name := range commandNames() {
+		commands = append(commands, shellQuote(name+":"+strings.ReplaceAll(descriptions[name], ":", `\:`)))
+	}
+
+	// Each option is an _arguments spec like '(-b --bits)-b+[Bit width]:BITS:action' for each form
+	specs := []string{}
+	escape := strings.NewReplacer(`\`, `\\`, "[", `\[`, "]", `\]`, ":", `\:`)
+	for _, option := range OPTIONS {
+		exclusion := ""
+		if option.Short != 0 {
+			exclusion = "(" + strings.Join(optionForms(option), " ") + ")"
+		}
+		argument := ""
+		if option.Metavar != "" {
+			action := ""
+			switch {
+			case option.Values != nil && option.Metavar == "NAMES":
+				action = "_sequence compadd - " + strings.Join(option.Values(), " ")
+			case option.Values != nil:
+				action = "(" + strings.Join(option.Values(), " ") + ")"
+			case option.Metavar == "FILE":
+				action = "_files"
+			}
+			argument = ":" + option.Metavar + ":" + action
+		}
+		for _, form := range optionForms(option) {
+			switch {
+			case option.Metavar == "":
+			case strings.HasPrefix(form, "--"):
+				form += "="
+			default:
+				form += "+"
+			}
+			specs = append(specs, shellQuote(exclusion+form+"["+escape.Replace(option.Description)+"]"+argument))
+		}
+	}
+	return completionScript(ZSH_COMPLETION, map[string]interface{}{
+		"Shells":   strings.Join(SHELLS, " "),
+		"Commands": commands,
+		"Specs":    specs,
+	})
+}
+
+// Writes the completion script for the shell named in the arguments
+func RunCompletion(args []string, w io.Writer) error {
+	if len(args) != 1 {
+		return usageError("expected a shell: %s", strings.Join(SHELLS, ", "))
+	}
+	switch args[0] {
+	case "bash":
+		fmt.Fprint(w, bashCompletion())
+	case "zsh":
+		fmt.Fprint(w, zshCompletion())
+	case "fish":
+		fmt.Fprint(w, fishCompletion())
+	default:
+		return usageError("unknown shell '%s', expected one of %s", args[0], strings.Join(SHELLS, ", "))
+	}
+	return nil
+}
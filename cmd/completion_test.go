@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCompletionScriptsListEverything(t *testing.T) {
+	scripts := map[string]string{
+		"bash": bashCompletion(),
+		"zsh":  zshCompletion(),
+		"fish": fishCompletion(),
+		"man":  strings.ReplaceAll(manPage(), `\-`, "-"),
+	}
+	for name, script := range scripts {
+		for _, option := range OPTIONS {
+			if !strings.Contains(script, option.Long) {
+				t.Errorf("%s does not contain the option %s", name, option.Name())
+			}
+			if option.Values == nil {
+				continue
+			}
+			for _, value := range option.Values() {
+				if !strings.Contains(script, value) {
+					t.Errorf("%s does not contain the value %s for %s", name, value, option.Name())
+				}
+			}
+		}
+		for _, command := range commandNames() {
+			if !strings.Contains(script, command) {
+				t.Errorf("%s does not contain the command %s", name, command)
+			}
+		}
+	}
+}
+
+func TestRoffEscape(t *testing.T) {
+	for input, expected := range map[string]string{
+		"--bits":    `\-\-bits`,
+		`a\b`:       `a\eb`,
+		".hidden":   `\&.hidden`,
+		"'quoted'":  `\&'quoted'`,
+		"plain 0x1": "plain 0x1",
+	} {
+		if actual := roffEscape(input); actual != expected {
+			t.Errorf("roffEscape(%q) = %q, expected %q", input, actual, expected)
+		}
+	}
+}
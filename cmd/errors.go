@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"github.com/jonathangjertsen/jco-go/ops"
+)
+
+// Exit statuses, so that scripts can tell what went wrong
+const (
+	EXIT_OK             = 0
+	EXIT_ERROR          = 1
+	EXIT_USAGE          = 2
+	EXIT_INVALID_NUMBER = 3
+	EXIT_OVERFLOW       = 4
+	EXIT_INVALID_RESULT = 5
+)
+
+// The result of an operation was printed, but it is meaningless for the numbers, e.g. because they are not valid BCD
+var ErrInvalidResult = errors.New("invalid result")
+
+// An exit status and what it means, shown in the help text and the man page
+type ExitStatus struct {
+	Code        int
+	Description string
+}
+
+// An error in how jco was invoked, such as an unknown option or a bad option value
+type UsageError struct {
+	message string
+}
+
+var EXIT_STATUSES = []ExitStatus{
+	{EXIT_OK, "Success"},
+	{EXIT_ERROR, "Error, e.g. a file could not be read or lines in --batch mode could not be evaluated"},
+	{EXIT_USAGE, "Invalid arguments or options"},
+	{EXIT_INVALID_NUMBER, "Invalid number"},
+	{EXIT_OVERFLOW, "A number does not fit in the bit width"},
+	{EXIT_INVALID_RESULT, "The result of an operation is meaningless for the numbers, e.g. they are not valid BCD"},
+}
+
+// Returns a UsageError with a formatted message
+func usageError(format string, a ...interface{}) error {
+	return UsageError{fmt.Sprintf(format, a...)}
+}
+
+// Returns the exit status that jco should exit with after the error
+func ExitCode(err error) int {
+	var usage UsageError
+	switch {
+	case err == nil:
+		return EXIT_OK
+	case errors.As(err, &usage):
+		return EXIT_USAGE
+	case errors.Is(err, ops.ErrInvalidNumber):
+		return EXIT_INVALID_NUMBER
+	case errors.Is(err, ops.ErrOverflow):
+		return EXIT_OVERFLOW
+	case errors.Is(err, ErrInvalidResult):
+		return EXIT_INVALID_RESULT
+	default:
+		return EXIT_ERROR
+	}
+}
+
+func (e UsageError) Error() string {
+	return e.message
+}
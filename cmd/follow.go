@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"bufio"
+
+	"encoding/csv"
+	"fmt"
+	"github.com/jonathangjertsen/jco-go/jco"
+	"regexp"
+)
+
+const (
+	// Pattern used to find values in --follow mode if --pattern is not given
+	DEFAULT_FOLLOW_PATTERN = `\b(0[xX][0-9a-fA-F]+|[0-9]+)\b`
+)
+
+// Decodes one value found in --follow mode, or returns an empty result if it should not be shown
+func followMatch(lineNumber int, position int, text string, previous map[int]jco.Value, flags *Flags) batchResult {
+	value, err := flags.parseNumber(text)
+	if err != nil {
+		return formatTable(lineNumber, text, nil, err, flags)
+	}
+	if flags.changes {
+		if last, seen := previous[position]; seen && last.Equal(value) {
+			return batchResult{}
+		}
+		previous[position] = value
+	}
+	t := newTable(flags)
+	t.One(value, text)
+	if flags.register != nil {
+		t.Fields(value, flags.register)
+	}
+	return formatTable(lineNumber, text, t, nil, flags)
+}
+
+// Reads stdin line by line, decodes every value matched by the pattern and prints the results as soon as each
+// line arrives. If the pattern has a capture group, the first group is the value, otherwise it is the whole match.
+// With --changes, a value is only printed if it differs from the previous value in the same position on a line
+func RunFollow(flags *Flags) error {
+	pattern, err := regexp.Compile(flags.pattern)
+	if err != nil {
+		return usageError("invalid value for --pattern: %v", err)
+	}
+
+	output := bufio.NewWriter(flags.streams.Out)
+	if flags.outputFormat == "csv" {
+		csvWriter := csv.NewWriter(output)
+		csvWriter.Write(append([]string{"input"}, newTable(flags).Columns()...))
+		csvWriter.Flush()
+	}
+	previous := map[int]jco.Value{}
+	scanner := bufio.NewScanner(flags.streams.In)
+	scanner.Buffer(nil, BATCH_MAX_LINE_LENGTH)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		for i, match := range pattern.FindAllStringSubmatch(scanner.Text(), -1) {
+			text := match[0]
+			if len(match) > 1 {
+				text = match[1]
+			}
+			result := followMatch(lineNumber, i, text, previous, flags)
+			output.WriteString(result.output)
+			if result.err != nil && flags.outputFormat != "json" {
+				output.Flush()
+				fmt.Fprintf(flags.streams.Err, "jco: %v\n", result.err)
+			}
+		}
+		output.Flush()
+	}
+	return scanner.Err()
+}
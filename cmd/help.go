@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+)
+
+// A command which is given as the first argument instead of a number, shown in the completions and the man page
+type Command struct {
+	Name        string
+	Description string
+}
+
+// An example of how to use jco, shown in the help text and the man page
+type Example struct {
+	Description string
+	Command     string
+}
+
+// Example config file, shown in the help text and the man page
+const CONFIG_EXAMPLE = `bits = 16
+group = true
+columns = "formula,hex,bin"
+
+[profile.cortex-m]
+bits = 32
+register = "EN[0],MODE[3:1]"
+`
+
+var COMMANDS = []Command{
+	{"completion", "Print a completion script for bash, zsh or fish"},
+	{"man", "Print the man page"},
+	{"serve", "Serve the analyses as JSON over HTTP, with a web page for toggling bits"},
+	{"rpc", "Answer JSON-RPC requests on stdin, for editor integration"},
+	{"tui", "Edit a value bit by bit in a full-screen view"},
+	{"c", "Show how C promotes, converts and evaluates integer expressions"},
+}
+
+var EXAMPLES = []Example{
+	{"Show information about <number>", "jco <number>"},
+	{"Show information about how <number1> and <number2> relate", "jco <number1> <number2>"},
+	{"Like the above, but treat numbers as 16-bit", "jco <number1> <number2> -b 16"},
+	{"Group the digits (binary and hex by 4, decimal by 3) and label the bit indices above the binary column", "jco <number> --group --ruler"},
+	{"Choose the group sizes and separator yourself (a group size of 0 disables grouping)", `jco <number> --group-bin 8 --group-hex 2 --group-dec 3 --group-sep "'"`},
+	{"Only show some of the operations, in the given order", "jco <number> --ops popcount,clz,reverse_byteorder"},
+	{"Hide some of the operations or columns (prefix the name with -)", "jco <number> --ops -nbits,-clz --columns -bin"},
+	{"Only show the decimal and hexadecimal columns", "jco <number> --columns formula,dec,hex"},
+	{"Print only the result of one operation, for use in scripts", "jco <operation> <number> [<number2>] [--as dec|hex|bin]"},
+	{"Evaluate every line in a file (or stdin if the file is -), where each line has one or two numbers or an expression", "jco --batch <file> [--format table|json|csv] [--jobs <number of workers, default is the number of CPUs>]"},
+	{"Show each byte on its own row with its index, bit range and ASCII, numbered from the least significant byte with --little-index", "jco <number> --bytes [--little-index]"},
+	{"Read the numbers in a base from 2 to 64 or in a named encoding, and add columns with other bases and encodings", "jco <number> --in-base base58 --out-base 36 --columns formula,dec,base,base32,base64"},
+	{"Give a number as a character, a string (UTF-8, with C escapes) or a Unicode codepoint", `jco "'A'" | jco '"abc"' | jco U+1F600`},
+	{"Show the bytes as ASCII, Latin-1 and a C string, and the number as a codepoint in UTF-8, UTF-16 and UTF-32", "jco <number> --text"},
+	{"Show the packed BCD of the number and the number that the bytes are in packed or unpacked BCD, where ! marks invalid digits", "jco <number> --ops value,bcd,from_bcd,from_unpacked_bcd"},
+	{"Add or subtract packed BCD numbers with decimal adjust, like the DAA and DAS instructions", "jco <number1> <number2> --ops bcd_add,bcd_sub"},
+	{"Show the fields of a register, given as a list of fields or a file with one field per line", "jco <number> --register 'EN[0],MODE[3:1],DIV[15:8]'"},
+	{"Decode values from each line of stdin as it arrives, optionally only when they change", "tail -f uart.log | jco --follow --pattern 'REG=(0x[0-9a-f]+)' [--changes] [--register <fields>]"},
+	{"Use a named profile from the config file", "jco <number> --profile cortex-m"},
+	{"Serve /api/one?x=<number>&bits=<bits>, /api/two?a=<number1>&b=<number2> and /api/eval?expr=<expression> as JSON, and a web page on /", "jco serve [--addr 127.0.0.1:8080]"},
+	{"Print a shell completion script, e.g. for bash: source <(jco completion bash)", "jco completion bash|zsh|fish"},
+	{"Show the man page", "jco man | man -l -"},
+	{"Edit the bits of a number in a full-screen view (arrows move, space toggles, q quits and prints the value), optionally with register fields", "jco tui [<number>] [--register <fields>]"},
+	{"Show the type and value after each integer promotion and conversion in C, and flag undefined and implementation-defined behavior, for a data model (or read the program from stdin with -)", "jco c 'uint8_t a = 0xf0; int16_t b = -3; a << 4; a + b' [--abi lp64|ilp32|llp64]"},
+	{"Answer JSON-RPC 2.0 requests (convert, evaluate, decodeRegister) on stdin, one per line or with Content-Length headers", `echo '{"jsonrpc": "2.0", "id": 1, "method": "convert", "params": {"text": "0x1877"}}' | jco rpc`},
+	{"Show this help screen", "jco --help"},
+	{"Show one-liner version", "jco --version"},
+}
+
+// Returns the help text listing the examples
+func exampleList() string {
+	var builder strings.Builder
+	for _, example := range EXAMPLES {
+		builder.WriteString(fmt.Sprintf("\t%s\n\t\t%s\n\n", example.Description, example.Command))
+	}
+	return builder.String()
+}
+
+// Returns the help text listing the exit statuses
+func exitStatusList() string {
+	var builder strings.Builder
+	for _, status := range EXIT_STATUSES {
+		builder.WriteString(fmt.Sprintf("\t%d\t%s\n", status.Code, status.Description))
+	}
+	return builder.String()
+}
+
+// Returns the text with the prefix added to every non-empty line
+func indent(text string, prefix string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = prefix + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
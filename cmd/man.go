@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/jonathangjertsen/jco-go/config"
+	"github.com/jonathangjertsen/jco-go/ops"
+	"github.com/jonathangjertsen/jco-go/table"
+	"io"
+	"strings"
+)
+
+// Returns the man page in roff format
+func manPage() string {
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf(".TH JCO 1 \"\" \"jco %s\" \"User Commands\"\n", VERSION))
+	builder.WriteString(".SH NAME\njco \\- Jonathan's converter, shows a number in many bases and the results of bitwise operations\n")
+
+	builder.WriteString(".SH SYNOPSIS\n")
+	builder.WriteString(".B jco\n[\\fIOPTIONS\\fR] \\fInumber\\fR [\\fInumber2\\fR]\n.br\n")
+	builder.WriteString(".B jco\n\\fIoperation\\fR [\\fIOPTIONS\\fR] \\fInumber\\fR [\\fInumber2\\fR]\n.br\n")
+	for _, command := range COMMANDS {
+		builder.WriteString(".B jco " + roffEscape(command.Name) + "\n.br\n")
+	}
+
+	builder.WriteString(".SH DESCRIPTION\n")
+	builder.WriteString("Shows a number in decimal, hexadecimal and binary along with the results of operations on it, " +
+		"or the results of operations on two numbers. Given an operation name first, prints only the result of that operation.\n")
+
+	builder.WriteString(".SH COMMANDS\n")
+	for _, command := range COMMANDS {
+		builder.WriteString(".TP\n.B " + roffEscape(command.Name) + "\n" + roffEscape(command.Description) + "\n")
+	}
+
+	builder.WriteString(".SH OPTIONS\n")
+	for _, option := range OPTIONS {
+		builder.WriteString(".TP\n")
+		usage := "\\fB" + roffEscape(option.Name()) + "\\fR"
+		if option.Short != 0 {
+			usage = fmt.Sprintf("\\fB\\-%c\\fR, %s", option.Short, usage)
+		}
+		if option.Metavar != "" {
+			usage += " \\fI" + roffEscape(option.Metavar) + "\\fR"
+		}
+		builder.WriteString(usage + "\n" + roffEscape(option.Description))
+		if option.Default != "" && option.Metavar != "" {
+			builder.WriteString(" (default: " + roffEscape(option.Default) + ")")
+		}
+		builder.WriteString("\n")
+		if option.Values != nil {
+			builder.WriteString(".br\nValues: " + roffEscape(strings.Join(option.Values(), ", ")) + "\n")
+		}
+	}
+
+	sections := map[int]string{
+		1: "OPERATIONS ON ONE NUMBER",
+		2: "OPERATIONS ON TWO NUMBERS",
+	}
+	for _, arity := range []int{1, 2} {
+		builder.WriteString(".SH " + sections[arity] + "\n")
+		for _, op := range ops.Operations(arity) {
+			builder.WriteString(".TP\n.B " + roffEscape(op.Name) + "\n")
+			builder.WriteString(strings.Join(roffLines(op.Description), "\n.br\n") + "\n")
+		}
+	}
+
+	builder.WriteString(".SH COLUMNS\nNames to use with \\fB\\-\\-columns\\fR: " + roffEscape(strings.Join(table.ColumnNames(), ", ")) + "\n")
+
+	builder.WriteString(".SH EXAMPLES\n")
+	for _, example := range EXAMPLES {
+		builder.WriteString(".TP\n" + roffEscape(example.Description) + "\n.nf\n")
+		builder.WriteString(strings.Join(roffLines(example.Command), "\n") + "\n.fi\n")
+	}
+
+	builder.WriteString(".SH FILES\n")
+	builder.WriteString(".TP\n.I " + roffEscape(globalConfigPath()) + "\nDefaults for the options, using the long option names\n")
+	builder.WriteString(".TP\n.I " + roffEscape(config.LOCAL_FILE_NAME) + "\n" +
+		"Project-local defaults, read from the current directory or the nearest parent, which override the user's config file\n")
+	builder.WriteString(".PP\nExample:\n.PP\n.RS\n.nf\n" + strings.Join(roffLines(strings.TrimSuffix(CONFIG_EXAMPLE, "\n")), "\n") + "\n.fi\n.RE\n")
+
+	builder.WriteString(".SH EXIT STATUS\n")
+	for _, status := range EXIT_STATUSES {
+		builder.WriteString(fmt.Sprintf(".TP\n.B %d\n%s\n", status.Code, roffEscape(status.Description)))
+	}
+	return builder.String()
+}
+
+// Escapes text for use in roff, so that backslashes and dashes are printed as they are
+func roffEscape(text string) string {
+	text = strings.NewReplacer(`\`, `\e`, "-", `\-`).Replace(text)
+	if strings.HasPrefix(text, ".") || strings.HasPrefix(text, "'") {
+		text = `\&` + text
+	}
+	return text
+}
+
+// Escapes each line of the text for use in roff
+func roffLines(text string) []string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = roffEscape(line)
+	}
+	return lines
+}
+
+// Writes the man page
+func RunMan(args []string, w io.Writer) error {
+	if len(args) != 0 {
+		return usageError("man takes no arguments")
+	}
+	fmt.Fprint(w, manPage())
+	return nil
+}
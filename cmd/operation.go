@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"github.com/jonathangjertsen/jco-go/jco"
+	"github.com/jonathangjertsen/jco-go/ops"
+	"strings"
+)
+
+// Returns whether the argument names a registered operation, in which case it is used as a subcommand
+func isOperationName(arg string) bool {
+	for _, arity := range []int{1, 2} {
+		if _, ok := ops.LookupOperation(arg, arity); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Prints only the result of the named operation on the numbers in flags, truncated to the bit width. If the result
+// is meaningless for the numbers, e.g. because they are not valid BCD, it is still printed, and the error says why
+func RunOperation(name string, flags *Flags) error {
+	if len(flags.numbers) == 0 {
+		return usageError("%s does not take 0 numbers", name)
+	}
+	result, _, err := flags.numbers[0].Apply(name, flags.numbers[1:]...)
+	if errors.Is(err, jco.ErrUnknownOperation) {
+		return usageError("%s does not take %d numbers", name, len(flags.numbers))
+	} else if err != nil {
+		return err
+	}
+
+	var output string
+	switch flags.as {
+	case "dec":
+		output = result.DecGrouped(flags.format.GroupDec, flags.format.Separator)
+	case "hex":
+		output = result.HexGrouped(flags.format.GroupHex, flags.format.Separator)
+	case "bin":
+		output = result.BinGrouped(flags.format.GroupBin, flags.format.Separator)
+	default:
+		return usageError("invalid value for --as: %s, expected dec, hex or bin", flags.as)
+	}
+	fmt.Fprintln(flags.streams.Out, output)
+	if err := flags.numbers[0].Check(name, flags.numbers[1:]...); err != nil {
+		return fmt.Errorf("%w: %s(%s): %v", ErrInvalidResult, name, strings.Join(flags.numbersAsWritten, ", "), err)
+	}
+	return nil
+}
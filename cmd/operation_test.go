@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"bytes"
+	"github.com/jonathangjertsen/jco-go/jco"
+	"strings"
+	"testing"
+)
+
+// Runs jco with the operation as the subcommand and returns what it printed to stdout
+func runOperationOutput(name string, args []string) (string, error) {
+	var stdout bytes.Buffer
+	err := run(append([]string{name}, args...), Streams{strings.NewReader(""), &stdout, &bytes.Buffer{}})
+	return stdout.String(), err
+}
+
+func TestRunOperation(t *testing.T) {
+	isolateConfig(t)
+	var vector = []struct {
+		name string
+		op   string
+		args []string
+		want string
+	}{
+		{"dec", "popcount", []string{"0x1877"}, "8\n"},
+		{"hex", "reverse_byteorder", []string{"-b", "16", "0x1234", "--as", "hex"}, "0x3412\n"},
+		{"bin", "add", []string{"-b", "8", "5", "6", "--as", "bin"}, "0b00001011\n"},
+		{"truncated to the width", "add", []string{"-b", "8", "0xff", "1", "--as", "hex"}, "0x00\n"},
+		{"grouped", "add", []string{"-b", "16", "0x1200", "0x34", "--as", "hex", "--group-hex", "2"}, "0x12_34\n"},
+	}
+	for _, tt := range vector {
+		t.Run(tt.name, func(t *testing.T) {
+			have, err := runOperationOutput(tt.op, tt.args)
+			if err != nil {
+				t.Fatalf("Want no error, have %v", err)
+			}
+			if have != tt.want {
+				t.Errorf("Want %q, have %q", tt.want, have)
+			}
+		})
+	}
+}
+
+func TestRunOperationApplyErrors(t *testing.T) {
+	one, _ := jco.FromUint64(1, 8)
+	streams := Streams{strings.NewReader(""), &bytes.Buffer{}, &bytes.Buffer{}}
+
+	// Errors other than an unknown operation, like a width that the operand cannot be resized to, are not usage errors
+	err := RunOperation("add", &Flags{numbers: []jco.Value{{}, one}, as: "hex", streams: streams})
+	if err == nil || ExitCode(err) == EXIT_USAGE || strings.Contains(err.Error(), "does not take") {
+		t.Errorf("Want the error from the operation, have %v", err)
+	}
+}
+
+func TestRunOperationErrors(t *testing.T) {
+	isolateConfig(t)
+	var vector = []struct {
+		name   string
+		op     string
+		args   []string
+		want   string
+		status int
+	}{
+		{"too many numbers", "popcount", []string{"1", "2"}, "popcount does not take 2 numbers", EXIT_USAGE},
+		{"too few numbers", "add", []string{"1"}, "add does not take 1 numbers", EXIT_USAGE},
+		{"invalid --as", "popcount", []string{"1", "--as", "oct"}, "invalid value for --as: oct", EXIT_USAGE},
+		{"invalid number", "popcount", []string{"0x1g"}, "invalid number '0x1g'", EXIT_INVALID_NUMBER},
+		{"overflow", "popcount", []string{"-b", "8", "0x100"}, "0x0100 does not fit in 8 bits", EXIT_OVERFLOW},
+	}
+	for _, tt := range vector {
+		t.Run(tt.name, func(t *testing.T) {
+			have, err := runOperationOutput(tt.op, tt.args)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Want an error containing %q, have %v", tt.want, err)
+			}
+			if status := ExitCode(err); status != tt.status {
+				t.Errorf("Want exit status %d, have %d", tt.status, status)
+			}
+			if have != "" {
+				t.Errorf("Want no output, have %q", have)
+			}
+		})
+	}
+}
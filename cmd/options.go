@@ -0,0 +1,338 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/jonathangjertsen/jco-go/cint"
+	"github.com/jonathangjertsen/jco-go/config"
+	"github.com/jonathangjertsen/jco-go/ops"
+	"github.com/jonathangjertsen/jco-go/table"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// A command line option. The parser, the help text and everything else that lists options is driven by OPTIONS
+type Option struct {
+	// Name used as --name
+	Long string
+
+	// Letter used as -x, or 0 if there is no short form
+	Short byte
+
+	// Placeholder for the value in the help text, or "" if the option is a switch that takes no value
+	Metavar string
+
+	// Value used if the option is not given
+	Default string
+
+	// Human readable description, shown in the help text
+	Description string
+
+	// Returns the possible values for shell completion, or nil if they can not be listed
+	Values func() []string
+}
+
+// Command line arguments sorted into options and positional arguments
+type parsedArgs struct {
+	// Values of the options that were given, by long name. Switches have the value "true"
+	values map[string]string
+
+	// Arguments that are not options, in order
+	positional []string
+}
+
+var OPTIONS = []Option{
+	{"bits", 'b', "BITS", "32", "Bit width, rounded up to a multiple of 8 (1 to 64)", nil},
+	{"widths", 0, "BITS", "", "Comma-separated bit widths to show side by side, e.g. 8,16,32,64", nil},
+	{"bytes", 0, "", "", "Show each byte of the numbers on its own row, with its index, bit range and ASCII", nil},
+	{"little-index", 0, "", "", "With --bytes, number the bytes from the least significant, as in memory on little-endian machines", nil},
+	{"in-base", 0, "BASE", "", "Read numbers in a base from 2 to 64, or a named encoding (" + strings.Join(ops.EncodingNames(), ", ") + ")", inBaseNames},
+	{"out-base", 0, "BASE", "", "Add a column with the numbers in a base from 2 to 64 (digits 0-9, a-z, A-Z, - and _)", nil},
+	{"alphabet", 0, "DIGITS", "", "Digits for --in-base and --out-base, where base N uses the first N. Without --out-base, adds a base column using all of them", nil},
+	{"text", 0, "", "", "Show the bytes of the numbers as ASCII, Latin-1 and a C string, and the numbers as Unicode codepoints", nil},
+	{"auto-width", 0, "", "", "Infer the bit width from how numbers are written, e.g. 16 bits for 0x00ff", nil},
+	{"promote", 0, "RULE", "widest", "Type that numbers of different types are converted to: widest, c, or a type like i32", valuesOf("widest", "c")},
+	{"group", 'g', "", "", "Group binary and hex digits by 4 and decimal digits by 3", nil},
+	{"group-bin", 0, "N", "", "Number of binary digits per group (0 disables grouping)", nil},
+	{"group-hex", 0, "N", "", "Number of hex digits per group (0 disables grouping)", nil},
+	{"group-dec", 0, "N", "", "Number of decimal digits per group (0 disables grouping)", nil},
+	{"group-sep", 0, "SEP", "_", "Separator between groups of digits", nil},
+	{"ruler", 'r', "", "", "Label the bit indices above the binary column", nil},
+	{"ops", 0, "NAMES", "", "Comma-separated operations to show, in order (prefix with - to hide)", operationNames},
+	{"columns", 0, "NAMES", "", "Comma-separated columns to show, in order (prefix with - to hide)", table.ColumnNames},
+	{"as", 0, "FORMAT", "dec", "Output format for a single operation (dec, hex or bin)", valuesOf("dec", "hex", "bin")},
+	{"batch", 0, "FILE", "", "Evaluate each line of the file, or stdin if FILE is -", nil},
+	{"format", 0, "FORMAT", "table", "Output format in --batch and --follow mode (table, json or csv)", valuesOf("table", "json", "csv")},
+	{"jobs", 0, "N", strconv.Itoa(runtime.NumCPU()), "Number of workers in --batch mode", nil},
+	{"follow", 'f', "", "", "Decode values from each line of stdin as it arrives", nil},
+	{"pattern", 0, "REGEX", DEFAULT_FOLLOW_PATTERN, "Regular expression which finds the values in --follow mode", nil},
+	{"changes", 0, "", "", "Only show values that changed in --follow mode", nil},
+	{"register", 0, "FIELDS", "", "Register fields like 'EN[0],MODE[3:1]', or a file with one field per line", nil},
+	{"color", 0, "WHEN", "auto", "Highlight the header and truncated values (auto, always or never)", valuesOf("auto", "always", "never")},
+	{"endian", 0, "ORDER", "big", "Byte order of the numbers as written (big, or little for numbers copied from memory dumps)", valuesOf("big", "little")},
+	{"addr", 0, "ADDR", "127.0.0.1:8080", "Address to listen on with jco serve", nil},
+	{"abi", 0, "ABI", "lp64", "Data model for jco c (lp64, ilp32 or llp64)", cint.ABINames},
+	{"profile", 'p', "NAME", "", "Use the settings from [profile.NAME] in the config file", nil},
+	{"help", 'h', "", "", "Show this help screen", nil},
+	{"version", 'v', "", "", "Show one-liner version", nil},
+}
+
+// Returns an error for a key in the config file that is not an option, with a suggestion if there is a similar one
+func configKeyError(key string) error {
+	candidates := []string{}
+	for _, option := range OPTIONS {
+		candidates = append(candidates, option.Long)
+	}
+	if suggestion := suggest(key, candidates); suggestion != "" {
+		return usageError("unknown setting '%s' in config file, did you mean '%s'?", key, suggestion)
+	}
+	return usageError("unknown setting '%s' in config file", key)
+}
+
+// Returns the edit distance between a and b
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current := make([]int, len(b)+1)
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = ops.Intmin(ops.Intmin(previous[j]+1, current[j-1]+1), previous[j-1]+cost)
+		}
+		previous = current
+	}
+	return previous[len(b)]
+}
+
+// Returns the named encodings that --in-base accepts besides numbers, for completion
+func inBaseNames() []string {
+	return ops.EncodingNames()
+}
+
+// Returns whether the argument is meant to be a number, even if it can not be parsed as one
+func looksLikeNumber(arg string) bool {
+	return len(arg) > 0 && '0' <= arg[0] && arg[0] <= '9'
+}
+
+// Returns the option with the given long name
+func lookupLong(name string) (Option, bool) {
+	for _, option := range OPTIONS {
+		if option.Long == name {
+			return option, true
+		}
+	}
+	return Option{}, false
+}
+
+// Returns the option with the given short name
+func lookupShort(letter byte) (Option, bool) {
+	for _, option := range OPTIONS {
+		if option.Short != 0 && option.Short == letter {
+			return option, true
+		}
+	}
+	return Option{}, false
+}
+
+// Returns the names of all operations, which is what --ops accepts
+func operationNames() []string {
+	return append(ops.OperationNames(1), ops.OperationNames(2)...)
+}
+
+// Returns the help text listing the options
+func optionList() string {
+	var builder strings.Builder
+	for _, option := range OPTIONS {
+		builder.WriteString(fmt.Sprintf("\t%-24s%s\n", option.Usage(), option.Description))
+	}
+	return builder.String()
+}
+
+// Sorts the arguments into options and positional arguments. Supports --name value, --name=value, -x value,
+// -xvalue and combined switches like -gr. Everything after -- is positional, and so are negative numbers
+func parseArgs(args []string) (*parsedArgs, error) {
+	parsed := parsedArgs{
+		values: map[string]string{},
+	}
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+
+		// Returns the value for an option which takes one, either from the rest of the argument or from the next one
+		takeValue := func(option Option, rest string, hasRest bool) error {
+			if hasRest {
+				parsed.values[option.Long] = rest
+				return nil
+			}
+			if i+1 >= len(args) {
+				return usageError("option %s needs a value (%s)", option.Name(), option.Metavar)
+			}
+			i++
+			parsed.values[option.Long] = args[i]
+			return nil
+		}
+
+		switch {
+		case arg == "--":
+			parsed.positional = append(parsed.positional, args[i+1:]...)
+			return &parsed, nil
+		case strings.HasPrefix(arg, "--"):
+			name := arg[2:]
+			value := ""
+			hasValue := false
+			if equals := strings.Index(name, "="); equals >= 0 {
+				name, value, hasValue = name[:equals], name[equals+1:], true
+			}
+			option, ok := lookupLong(name)
+			if !ok {
+				return nil, unknownOptionError("--" + name)
+			}
+			if option.Metavar == "" {
+				if hasValue {
+					return nil, usageError("option %s does not take a value", option.Name())
+				}
+				parsed.values[option.Long] = "true"
+			} else if err := takeValue(option, value, hasValue); err != nil {
+				return nil, err
+			}
+		case len(arg) > 1 && arg[0] == '-' && !looksLikeNumber(arg[1:]):
+			// One or more short options, where the last one may take a value
+			for j := 1; j < len(arg); j++ {
+				option, ok := lookupShort(arg[j])
+				if !ok {
+					return nil, unknownOptionError("-" + string(arg[j]))
+				}
+				if option.Metavar == "" {
+					parsed.values[option.Long] = "true"
+					continue
+				}
+				if err := takeValue(option, arg[j+1:], j+1 < len(arg)); err != nil {
+					return nil, err
+				}
+				break
+			}
+		default:
+			parsed.positional = append(parsed.positional, arg)
+		}
+	}
+	return &parsed, nil
+}
+
+// Returns the value of every option: the default, overridden by the config file, overridden by the profile
+// (from the command line or the config file), overridden by the command line
+func resolveOptions(parsed *parsedArgs, conf *config.Config) (map[string]string, error) {
+	values := map[string]string{}
+	for _, option := range OPTIONS {
+		if option.Metavar == "" {
+			values[option.Long] = "false"
+		} else {
+			values[option.Long] = option.Default
+		}
+	}
+
+	// Check every setting, including those in profiles that are not used this time
+	sections := []map[string]string{conf.Defaults}
+	for _, settings := range conf.Profiles {
+		sections = append(sections, settings)
+	}
+	for _, settings := range sections {
+		for key, value := range settings {
+			option, ok := lookupLong(key)
+			if !ok || key == "help" || key == "version" {
+				return nil, configKeyError(key)
+			}
+			if option.Metavar == "" && value != "true" && value != "false" {
+				return nil, usageError("invalid value for %s in config file: %s, expected true or false", key, value)
+			}
+		}
+	}
+
+	layers := []map[string]string{conf.Defaults}
+	profile := parsed.values["profile"]
+	if profile == "" {
+		profile = conf.Defaults["profile"]
+	}
+	if profile != "" {
+		settings, ok := conf.Profiles[profile]
+		if !ok {
+			names := []string{}
+			for name := range conf.Profiles {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			return nil, usageError("unknown profile '%s', the config file has: %s", profile, strings.Join(names, ", "))
+		}
+		layers = append(layers, settings)
+	}
+	for _, layer := range layers {
+		for key, value := range layer {
+			values[key] = value
+		}
+	}
+
+	for key, value := range parsed.values {
+		values[key] = value
+	}
+	return values, nil
+}
+
+// Returns the candidate which is most similar to the word, or "" if none of them are similar enough
+func suggest(word string, candidates []string) string {
+	best := ""
+	bestDistance := 0
+	for _, candidate := range candidates {
+		distance := editDistance(word, candidate)
+		if distance <= ops.Intmax(1, len(candidate)/3) && (best == "" || distance < bestDistance) {
+			best = candidate
+			bestDistance = distance
+		}
+	}
+	return best
+}
+
+// Returns an error for an option that does not exist, with a suggestion if there is a similar one
+func unknownOptionError(name string) error {
+	suggestion := ""
+	if option, ok := lookupShort(name[len(name)-1]); ok && len(name) == 3 {
+		suggestion = fmt.Sprintf("-%c", option.Short)
+	} else {
+		candidates := []string{}
+		for _, option := range OPTIONS {
+			candidates = append(candidates, option.Name())
+		}
+		suggestion = suggest(name, candidates)
+	}
+	if suggestion != "" {
+		return usageError("unknown option '%s', did you mean '%s'?", name, suggestion)
+	}
+	return usageError("unknown option '%s', see jco --help", name)
+}
+
+// Returns a function which returns the given values, for Option.Values
+func valuesOf(values ...string) func() []string {
+	return func() []string { return values }
+}
+
+// Returns the option as it is written with the long name
+func (o Option) Name() string {
+	return "--" + o.Long
+}
+
+// Returns how the option is used, e.g. "-b, --bits BITS" or "    --ops NAMES"
+func (o Option) Usage() string {
+	usage := "    " + o.Name()
+	if o.Short != 0 {
+		usage = fmt.Sprintf("-%c, %s", o.Short, o.Name())
+	}
+	if o.Metavar != "" {
+		usage += " " + o.Metavar
+	}
+	return usage
+}
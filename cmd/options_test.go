@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/jonathangjertsen/jco-go/config"
+	"reflect"
+	"testing"
+)
+
+func TestParseArgs(t *testing.T) {
+	var vector = []struct {
+		args       []string
+		bits       string
+		switches   []string
+		positional []string
+	}{
+		{[]string{"0x1877"}, "", []string{}, []string{"0x1877"}},
+		{[]string{"-b", "16", "1"}, "16", []string{}, []string{"1"}},
+		{[]string{"-b16", "1"}, "16", []string{}, []string{"1"}},
+		{[]string{"--bits=16", "1"}, "16", []string{}, []string{"1"}},
+		{[]string{"1", "--bits", "16"}, "16", []string{}, []string{"1"}},
+		{[]string{"-grb8", "1"}, "8", []string{"group", "ruler"}, []string{"1"}},
+		{[]string{"-5", "-g"}, "", []string{"group"}, []string{"-5"}},
+		{[]string{"--", "-b", "--help"}, "", []string{}, []string{"-b", "--help"}},
+	}
+	for _, tt := range vector {
+		testname := fmt.Sprintf("%v\n", tt.args)
+		t.Run(testname, func(t *testing.T) {
+			have, err := parseArgs(tt.args)
+			if err != nil {
+				t.Fatalf("Want no error, have %v\n", err)
+			}
+			if have.values["bits"] != tt.bits {
+				t.Errorf("Want bits %v, have %v\n", tt.bits, have.values["bits"])
+			}
+			for _, name := range tt.switches {
+				if have.values[name] != "true" {
+					t.Errorf("Want switch %v, have %v\n", name, have.values)
+				}
+			}
+			if !reflect.DeepEqual(have.positional, tt.positional) && len(tt.positional)+len(have.positional) > 0 {
+				t.Errorf("Want positional %v, have %v\n", tt.positional, have.positional)
+			}
+		})
+	}
+}
+
+func TestParseArgsErrors(t *testing.T) {
+	var vector = []struct {
+		args []string
+		want string
+	}{
+		{[]string{"--bitz", "16"}, "unknown option '--bitz', did you mean '--bits'?"},
+		{[]string{"--b", "16"}, "unknown option '--b', did you mean '-b'?"},
+		{[]string{"-x"}, "unknown option '-x', see jco --help"},
+		{[]string{"-b"}, "option --bits needs a value (BITS)"},
+		{[]string{"--ruler=yes"}, "option --ruler does not take a value"},
+	}
+	for _, tt := range vector {
+		testname := fmt.Sprintf("%v\n", tt.args)
+		t.Run(testname, func(t *testing.T) {
+			_, err := parseArgs(tt.args)
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("Want %v, have %v\n", tt.want, err)
+			}
+			if ExitCode(err) != EXIT_USAGE {
+				t.Errorf("Want exit status %v, have %v\n", EXIT_USAGE, ExitCode(err))
+			}
+		})
+	}
+}
+
+func TestResolveOptions(t *testing.T) {
+	conf := &config.Config{
+		Defaults: map[string]string{"bits": "16", "group": "true", "columns": "dec"},
+		Profiles: map[string]map[string]string{"avr": {"bits": "8", "ruler": "true"}},
+	}
+	var vector = []struct {
+		args []string
+		want map[string]string
+	}{
+		{[]string{}, map[string]string{"bits": "16", "group": "true", "ruler": "false", "columns": "dec", "as": "dec"}},
+		{[]string{"-p", "avr"}, map[string]string{"bits": "8", "group": "true", "ruler": "true", "columns": "dec"}},
+		{[]string{"-p", "avr", "-b", "32"}, map[string]string{"bits": "32", "ruler": "true"}},
+		{[]string{"--columns", "hex"}, map[string]string{"bits": "16", "columns": "hex"}},
+	}
+	for _, tt := range vector {
+		testname := fmt.Sprintf("%v\n", tt.args)
+		t.Run(testname, func(t *testing.T) {
+			parsed, _ := parseArgs(tt.args)
+			have, err := resolveOptions(parsed, conf)
+			if err != nil {
+				t.Fatalf("Want no error, have %v\n", err)
+			}
+			for key, value := range tt.want {
+				if have[key] != value {
+					t.Errorf("Want %v=%v, have %v\n", key, value, have[key])
+				}
+			}
+		})
+	}
+
+	// Unknown profiles and settings are usage errors
+	parsed, _ := parseArgs([]string{"-p", "arm"})
+	if _, err := resolveOptions(parsed, conf); ExitCode(err) != EXIT_USAGE {
+		t.Errorf("Want usage error for unknown profile, have %v\n", err)
+	}
+	parsed, _ = parseArgs([]string{})
+	if _, err := resolveOptions(parsed, &config.Config{Defaults: map[string]string{"bitz": "8"}}); ExitCode(err) != EXIT_USAGE {
+		t.Errorf("Want usage error for unknown setting, have %v\n", err)
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	candidates := []string{"popcount", "clz", "nbits"}
+	var vector = []struct {
+		word string
+		want string
+	}{
+		{"popcnt", "popcount"},
+		{"clz", "clz"},
+		{"clx", "clz"},
+		{"nbit", "nbits"},
+		{"0xzz", ""},
+	}
+	for _, tt := range vector {
+		t.Run(tt.word, func(t *testing.T) {
+			if have := suggest(tt.word, candidates); have != tt.want {
+				t.Errorf("Want %v, have %v\n", tt.want, have)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"github.com/jonathangjertsen/jco-go/table"
+	"strconv"
+)
+
+// One row of a table as it is returned by jco serve and jco rpc
+type rowRecord struct {
+	Operation string   `json:"operation"`
+	Operands  []string `json:"operands"`
+	Formula   string   `json:"formula"`
+	Dec       string   `json:"dec"`
+	Hex       string   `json:"hex"`
+	Bin       string   `json:"bin"`
+	Truncated bool     `json:"truncated"`
+	Invalid   string   `json:"invalid,omitempty"`
+}
+
+// Returns the rows of the table with the values formatted according to the format
+func rowRecords(t *table.Table, format table.Format) []rowRecord {
+	records := []rowRecord{}
+	for _, row := range t.Rows() {
+		records = append(records, rowRecord{
+			Operation: row.Operation,
+			Operands:  row.Operands,
+			Formula:   row.Formula,
+			Dec:       row.Value.DecGrouped(format.GroupDec, format.Separator),
+			Hex:       row.Value.HexGrouped(format.GroupHex, format.Separator),
+			Bin:       row.Value.BinGrouped(format.GroupBin, format.Separator),
+			Truncated: row.Truncated,
+			Invalid:   row.Invalid,
+		})
+	}
+	return records
+}
+
+// Returns a copy of the flags with the bit width and operations overridden, unless they are empty. Used for
+// requests to jco serve and jco rpc, which may choose these for themselves
+func (flags *Flags) withOverrides(bits string, operations string) (*Flags, error) {
+	overridden := *flags
+	if bits != "" {
+		bitsU64, err := strconv.ParseUint(bits, 0, 8)
+		if err != nil || bitsU64 < 1 || bitsU64 > 64 {
+			return nil, usageError("invalid value for bits: %s", bits)
+		}
+		overridden.bits = 8 * ((uint(bitsU64) + 7) / 8)
+		if err := overridden.register.CheckWidth(overridden.bits); err != nil {
+			return nil, usageError("invalid value for bits: %v", err)
+		}
+	}
+	if operations != "" {
+		overridden.operations = table.ParseSelection(operations)
+		if err := overridden.operations.Validate(operationNames()); err != nil {
+			return nil, usageError("invalid value for ops: %v", err)
+		}
+	}
+	return &overridden, nil
+}
@@ -1,103 +1,434 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"github.com/jonathangjertsen/jco-go/cint"
+	"github.com/jonathangjertsen/jco-go/config"
+	"github.com/jonathangjertsen/jco-go/jco"
 	"github.com/jonathangjertsen/jco-go/ops"
+	"github.com/jonathangjertsen/jco-go/register"
 	"github.com/jonathangjertsen/jco-go/table"
+	"io"
 	"math/big"
 	"os"
 	"strconv"
+	"strings"
 )
 
 const (
 	VERSION = "v1.0.1"
 )
 
+// The streams that jco reads from and writes to, so that it can be run without the real ones, e.g. in tests
+type Streams struct {
+	In  io.Reader
+	Out io.Writer
+	Err io.Writer
+}
+
 type Flags struct {
+	streams          Streams
 	bits             uint
+	widths           []uint
+	bytes            bool
+	littleIndex      bool
+	text             bool
+	inBase           string
+	autoWidth        bool
+	promote          string
+	operands         []jco.Value
+	resultType       jco.Type
+	widthNote        string
+	as               string
+	batch            string
+	jobs             uint
+	follow           bool
+	pattern          string
+	changes          bool
+	register         register.Definition
+	outputFormat     string
+	format           table.Format
+	operations       table.Selection
+	columns          table.Selection
+	littleEndian     bool
+	addr             string
+	abi              cint.ABI
 	help             bool
 	version          bool
-	numbers          [][]byte
+	numbers          []jco.Value
 	numbersAsWritten []string
 }
 
-func parseFlags(args []string) *Flags {
-	flags := Flags{}
-	opts := map[string]string{
-		"-b": "32",
-	}
-	currentOpt := ""
-	for _, arg := range args {
-		if currentOpt == "" {
-			num, ok := new(big.Int).SetString(arg, 0)
-			if ok {
-				bytes := num.Bytes()
-				flags.numbers = append(flags.numbers, bytes)
-				flags.numbersAsWritten = append(flags.numbersAsWritten, arg)
-			} else {
-				switch arg {
-				case "-v", "--version":
-					flags.version = true
-				case "-h", "--help":
-					flags.help = true
-				default:
-					currentOpt = arg
-				}
-			}
-		} else {
-			opts[currentOpt] = arg
-			currentOpt = ""
+// Returns the path of the user's config file for the help text
+func globalConfigPath() string {
+	path, err := config.GlobalPath()
+	if err != nil {
+		return "$XDG_CONFIG_HOME/jco/config.toml"
+	}
+	return path
+}
+
+// Returns whether the stream is a terminal rather than e.g. a pipe or a buffer
+func isTerminal(stream interface{}) bool {
+	file, ok := stream.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// Returns a table set up with the formatting and selection in flags
+func newTable(flags *Flags) *table.Table {
+	t := table.NewTable(flags.bits)
+	t.SetFormat(flags.format)
+	t.SetOperations(flags.operations)
+	t.SetColumns(flags.columns)
+	return t
+}
+
+// Returns the help text listing the operations with the given arity, noting which ones are only shown if selected
+func operationList(arity int) string {
+	var builder strings.Builder
+	for _, op := range ops.Operations(arity) {
+		lines := strings.Split(op.Description, "\n")
+		if op.Optional {
+			lines[len(lines)-1] += " (only with --ops)"
+		}
+		builder.WriteString(fmt.Sprintf("\t%-24s%s\n", op.Name+":", lines[0]))
+		for _, line := range lines[1:] {
+			builder.WriteString(fmt.Sprintf("\t%-24s%s\n", "", line))
 		}
 	}
+	return builder.String()
+}
 
-	// Extracts 'bits' argument
-	bitsU64, err := strconv.ParseUint(opts["-b"], 0, 6)
+// Parses a base from 2 to 64 given with the option
+func parseBase(option string, text string) (uint, error) {
+	base, err := strconv.ParseUint(text, 10, 8)
+	if err != nil || base < ops.MIN_RADIX || base > ops.MAX_RADIX {
+		return 0, usageError("invalid value for --%s: %s, expected a base from %d to %d", option, text, ops.MIN_RADIX, ops.MAX_RADIX)
+	}
+	return uint(base), nil
+}
+
+// Parses a bit width given with the option, and rounds it up to a multiple of 8 with a warning
+func parseBits(option string, text string, streams Streams) (uint, error) {
+	bitsU64, err := strconv.ParseUint(text, 0, 8)
 	bits := uint(bitsU64)
 	if err != nil || bits < 1 || bits > 64 {
-		Fatal(fmt.Sprintf("Invalid value for -b: %s", opts["-b"]))
+		return 0, usageError("invalid value for --%s: %s", option, text)
 	}
 	if bits%8 != 0 {
 		bitsRounded := 8 * ((bits + 7) / 8)
-		fmt.Printf("Warning: -b %v is rounded up to %v\n\n", bits, bitsRounded)
+		fmt.Fprintf(streams.Err, "Warning: --%s %v is rounded up to %v\n\n", option, bits, bitsRounded)
 		bits = bitsRounded
 	}
-	flags.bits = bits
+	return bits, nil
+}
 
-	// Pad numbers up to bytes
-	for i, num := range flags.numbers {
-		nBytes := flags.bits / 8
-		nBytesInNum := uint(len(num))
-		if nBytesInNum < nBytes {
-			flags.numbers[i] = ops.PrependZeros(num, uint(nBytes-nBytesInNum))
+// Parses the arguments, where the positional arguments are numbers
+func parseFlags(args []string, streams Streams) (*Flags, error) {
+	flags, positional, err := parseOptions(args, streams)
+	if err != nil {
+		return nil, err
+	}
+
+	// Every positional argument must be a number that fits in the bit width, or has its own type
+	ownTypes := false
+	for _, arg := range positional {
+		num, ownType, err := flags.parseOperand(arg)
+		if errors.Is(err, ops.ErrInvalidNumber) && flags.inBase == "" {
+			suggestion := suggest(arg, operationNames())
+			if suggestion != "" {
+				return nil, fmt.Errorf("%w, did you mean the operation '%s'? (it has to come first)", err, suggestion)
+			}
+		}
+		if err != nil {
+			return nil, err
 		}
+		flags.numbers = append(flags.numbers, num)
+		flags.numbersAsWritten = append(flags.numbersAsWritten, arg)
+		ownTypes = ownTypes || ownType
 	}
 
-	return &flags
+	// Numbers of different types are converted to one result type, which decides the bit width
+	flags.operands = append([]jco.Value{}, flags.numbers...)
+	if len(flags.numbers) > 0 && len(flags.widths) == 0 && (ownTypes || flags.promote != "widest") {
+		if err := flags.promoteNumbers(); err != nil {
+			return nil, err
+		}
+	}
+	return flags, nil
 }
 
-func Execute() {
-	args := os.Args[1:]
-	flags := parseFlags(args)
+// Parses the options, and returns the positional arguments as they are
+func parseOptions(args []string, streams Streams) (*Flags, []string, error) {
+	flags := Flags{streams: streams}
+	parsed, err := parseArgs(args)
+	if err != nil {
+		return nil, nil, err
+	}
+	conf, err := config.Load()
+	if err != nil {
+		return nil, nil, usageError("invalid config file: %v", err)
+	}
+	opts, err := resolveOptions(parsed, conf)
+	if err != nil {
+		return nil, nil, err
+	}
+	flags.version = opts["version"] == "true"
+	flags.help = opts["help"] == "true"
+	flags.format.Ruler = opts["ruler"] == "true"
+	flags.follow = opts["follow"] == "true"
+	flags.changes = opts["changes"] == "true"
+	flags.autoWidth = opts["auto-width"] == "true"
+	flags.bytes = opts["bytes"] == "true"
+	flags.littleIndex = opts["little-index"] == "true"
+	flags.text = opts["text"] == "true"
+	flags.promote = opts["promote"]
+	group := opts["group"] == "true"
+
+	// Extracts byte order and color
+	switch opts["endian"] {
+	case "big", "little":
+		flags.littleEndian = opts["endian"] == "little"
+	default:
+		return nil, nil, usageError("invalid value for --endian: %s, expected big or little", opts["endian"])
+	}
+	switch opts["color"] {
+	case "always":
+		flags.format.Color = true
+	case "never":
+		flags.format.Color = false
+	case "auto":
+		flags.format.Color = isTerminal(streams.Out) && os.Getenv("NO_COLOR") == ""
+	default:
+		return nil, nil, usageError("invalid value for --color: %s, expected auto, always or never", opts["color"])
+	}
+
+	// Extracts 'bits' and 'widths' arguments. With --widths, the numbers are parsed with the widest width
+	flags.bits, err = parseBits("bits", opts["bits"], streams)
+	if err != nil {
+		return nil, nil, err
+	}
+	if opts["widths"] != "" {
+		flags.bits = 0
+		for _, text := range strings.Split(opts["widths"], ",") {
+			width, err := parseBits("widths", strings.TrimSpace(text), streams)
+			if err != nil {
+				return nil, nil, err
+			}
+			flags.widths = append(flags.widths, width)
+			flags.bits = ops.Uintmax(flags.bits, width)
+		}
+	}
+
+	// Extracts digit grouping arguments, where --group is shorthand for the most common grouping
+	if group {
+		flags.format.GroupBin = 4
+		flags.format.GroupHex = 4
+		flags.format.GroupDec = 3
+	}
+	for opt, size := range map[string]*uint{
+		"group-bin": &flags.format.GroupBin,
+		"group-hex": &flags.format.GroupHex,
+		"group-dec": &flags.format.GroupDec,
+	} {
+		if value := opts[opt]; value != "" {
+			sizeU64, err := strconv.ParseUint(value, 0, 8)
+			if err != nil {
+				return nil, nil, usageError("invalid value for --%s: %s", opt, value)
+			}
+			*size = uint(sizeU64)
+		}
+	}
+	flags.format.Separator = opts["group-sep"]
+
+	// Extracts the bases that numbers are read and shown in
+	flags.inBase = opts["in-base"]
+	if flags.inBase != "" {
+		if _, ok := ops.LookupEncoding(flags.inBase); !ok {
+			if _, err := parseBase("in-base", flags.inBase); err != nil {
+				return nil, nil, usageError("invalid value for --in-base: %s, expected a base from 2 to 64 or one of %s", flags.inBase, strings.Join(ops.EncodingNames(), ", "))
+			}
+		}
+	}
+	if opts["out-base"] != "" {
+		flags.format.OutBase, err = parseBase("out-base", opts["out-base"])
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	if alphabet := opts["alphabet"]; alphabet != "" {
+		if err := ops.CheckAlphabet(alphabet); err != nil {
+			return nil, nil, usageError("invalid value for --alphabet: %v", err)
+		}
+		if flags.format.OutBase == 0 {
+			flags.format.OutBase = uint(len(alphabet))
+		}
+		for _, base := range [][2]string{{"in-base", flags.inBase}, {"out-base", fmt.Sprint(flags.format.OutBase)}} {
+			if n, err := parseBase(base[0], base[1]); err == nil && n > uint(len(alphabet)) {
+				return nil, nil, usageError("invalid value for --%s: %s, the alphabet only has %d digits", base[0], base[1], len(alphabet))
+			}
+		}
+		flags.format.Alphabet = alphabet
+	}
+	flags.as = opts["as"]
+
+	// Extracts batch mode arguments
+	flags.batch = opts["batch"]
+	jobsU64, err := strconv.ParseUint(opts["jobs"], 0, 16)
+	if err != nil || jobsU64 < 1 {
+		return nil, nil, usageError("invalid value for --jobs: %s", opts["jobs"])
+	}
+	flags.jobs = uint(jobsU64)
+	flags.outputFormat = opts["format"]
+	switch flags.outputFormat {
+	case "table", "json", "csv":
+	default:
+		return nil, nil, usageError("invalid value for --format: %s", flags.outputFormat)
+	}
+
+	// Extracts row and column selection
+	flags.operations = table.ParseSelection(opts["ops"])
+	if err := flags.operations.Validate(operationNames()); err != nil {
+		return nil, nil, usageError("invalid value for --ops: %v", err)
+	}
+	flags.columns = table.ParseSelection(opts["columns"])
+	if err := flags.columns.Validate(table.ColumnNames()); err != nil {
+		return nil, nil, usageError("invalid value for --columns: %v", err)
+	}
+
+	// Extracts serve mode arguments
+	flags.addr = opts["addr"]
+
+	// Extracts the data model for jco c
+	abi, ok := cint.LookupABI(opts["abi"])
+	if !ok {
+		return nil, nil, usageError("invalid value for --abi: %s, expected %s", opts["abi"], strings.Join(cint.ABINames(), ", "))
+	}
+	flags.abi = abi
+
+	// Extracts follow mode and register arguments
+	flags.pattern = opts["pattern"]
+	if spec := opts["register"]; spec != "" {
+		definition, err := register.Load(spec)
+		if err == nil {
+			err = definition.CheckWidth(flags.bits)
+		}
+		if err != nil {
+			return nil, nil, usageError("invalid value for --register: %v", err)
+		}
+		flags.register = definition
+	}
+
+	return &flags, parsed.positional, nil
+}
+
+// Runs jco with the given arguments (not including the program name)
+func run(args []string, streams Streams) error {
+	if len(args) > 0 && args[0] == "completion" {
+		return RunCompletion(args[1:], streams.Out)
+	}
+	if len(args) > 0 && args[0] == "man" {
+		return RunMan(args[1:], streams.Out)
+	}
+	if len(args) > 0 && (args[0] == "serve" || args[0] == "rpc") {
+		flags, err := parseFlags(args[1:], streams)
+		if err != nil {
+			return err
+		}
+		if len(flags.numbers) > 0 {
+			return usageError("%s does not take numbers", args[0])
+		}
+		if args[0] == "serve" {
+			return RunServe(flags)
+		}
+		return RunRPC(flags)
+	}
+	if len(args) > 0 && args[0] == "c" {
+		return RunC(args[1:], streams)
+	}
+	if len(args) > 0 && args[0] == "tui" {
+		flags, err := parseFlags(args[1:], streams)
+		if err != nil {
+			return err
+		}
+		switch len(flags.numbers) {
+		case 0:
+			zero, _ := jco.FromUint64(0, flags.bits)
+			return RunTUI(zero, flags)
+		case 1:
+			return RunTUI(flags.numbers[0], flags)
+		default:
+			return usageError("tui takes at most 1 number, got %d", len(flags.numbers))
+		}
+	}
+	if len(args) > 0 && isOperationName(args[0]) {
+		flags, err := parseFlags(args[1:], streams)
+		if err != nil {
+			return err
+		}
+		return RunOperation(args[0], flags)
+	}
+	flags, err := parseFlags(args, streams)
+	if err != nil {
+		return err
+	}
 	if flags.version {
-		Version()
-		return
+		Version(streams.Out)
+		return nil
 	}
 	if flags.help {
-		Usage()
-		return
+		Usage(streams.Out)
+		return nil
+	}
+	if flags.text {
+		return RunText(flags)
+	}
+	if flags.bytes {
+		return RunBytes(flags)
+	}
+	if err := flags.checkOperations(); err != nil {
+		return err
+	}
+	if len(flags.widths) > 0 {
+		return RunWidths(flags)
+	}
+	if flags.batch != "" {
+		return RunBatch(flags.batch, flags)
+	}
+	if flags.follow {
+		return RunFollow(flags)
+	}
+	t := newTable(flags)
+	if flags.widthNote != "" {
+		fmt.Fprintf(streams.Out, "%s\n\n", flags.widthNote)
 	}
-	t := table.NewTable(flags.bits)
 
 	switch len(flags.numbers) {
 	case 0:
-		Usage()
-		return
+		Usage(streams.Out)
+		return nil
 	case 1:
-		t.One(
-			flags.numbers[0],
-			flags.numbersAsWritten[0],
-		)
+		if flags.widthNote != "" {
+			t.OneAs(flags.operands[0], flags.numbersAsWritten[0], flags.resultType)
+		} else {
+			t.One(
+				flags.numbers[0],
+				flags.numbersAsWritten[0],
+			)
+		}
+		if flags.register != nil {
+			t.Fields(flags.numbers[0], flags.register)
+		}
 	case 2:
+		if flags.widthNote != "" {
+			t.TwoAs(flags.operands[0], flags.operands[1], flags.numbersAsWritten[0], flags.numbersAsWritten[1], flags.resultType)
+			break
+		}
 		t.Two(
 			flags.numbers[0],
 			flags.numbers[1],
@@ -105,52 +436,226 @@ func Execute() {
 			flags.numbersAsWritten[1],
 		)
 	default:
+		return usageError("expected 1 or 2 numbers, got %d", len(flags.numbers))
 	}
-	t.Render()
+	return t.RenderTo(streams.Out)
 }
 
-func Fatal(message string) {
-	panic(message)
+// Runs jco with the command line arguments, and returns the exit status
+func Execute() int {
+	err := run(os.Args[1:], Streams{os.Stdin, os.Stdout, os.Stderr})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "jco: %v\n", err)
+	}
+	return ExitCode(err)
 }
 
 func Interactive() {
 	fmt.Printf("Interactive")
 }
 
-func Usage() {
-	fmt.Printf(`jco (Jonathan's converter) %s
+func Usage(w io.Writer) {
+	fmt.Fprintf(w, `jco (Jonathan's converter) %s
 
 Usage:
 
-	Show information about <number>
-		jco <number>
+%sOptions:
 
-	Show information about how <number1> and <number2> relate
-		jco <number1> <number2>
+%s
+Below is a list of the operations when running jco <number> (names to use with --ops):
 
-	Like the above, but treat numbers as 16-bit
-		jco <number1> <number2> -b 16
+%s
+Below is a list of the operations when running jco <number1> <number2> (names to use with --ops):
 
-	Show this help screen
-		jco --help
+%s
+Names to use with --columns:
 
-	Show one-liner version
-		jco --version
+	%s
 
-Below is a list of the operations when running jco <number>:
+Config files:
 
-	twos_complement:        Two's complement (depends on bit width)
-	popcount:               Number of bits that are 1
-	clz:                    Number of leading zeros
-	nbits:                  Number of bits needed to represent the number
-	reverse_bitorder        Reverses the bit order within each byte    (0b11100011 -> 0b11000111)
-	reverse_nibbleorder     Reverses the nibble order within each byte (0xab -> 0xba)
-	reverse_byteorder       Reverses the byte order
-	reverse_bitstring       Interprets the input as a stream of bits, and reverses them.
-	                        Equivalent to reverse_bitorder followed by reverse_byteorder.
-`, VERSION)
+	Defaults for any of the options above can be set in %s
+	and in a project-local %s file in the current directory or a parent, using the long option names:
+
+%s
+Exit status:
+
+%s`,
+		VERSION,
+		exampleList(),
+		optionList(),
+		operationList(1),
+		operationList(2),
+		strings.Join(table.ColumnNames(), ","),
+		globalConfigPath(),
+		config.LOCAL_FILE_NAME,
+		indent(CONFIG_EXAMPLE, "\t\t"),
+		exitStatusList(),
+	)
 }
 
-func Version() {
-	fmt.Printf("jco %s", VERSION)
+func Version(w io.Writer) {
+	fmt.Fprintf(w, "jco %s", VERSION)
+}
+
+// Returns a usage error if --ops leaves no operation that takes as many numbers as were given, since the table would
+// then be empty
+func (flags *Flags) checkOperations() error {
+	arity := len(flags.numbers)
+	if arity < 1 || arity > 2 {
+		return nil
+	}
+	if len(flags.operations.ApplyDefaults(ops.OperationNames(arity), ops.DefaultOperationNames(arity))) == 0 {
+		return usageError("invalid value for --ops: none of the selected operations take %d numbers", arity)
+	}
+	return nil
+}
+
+// Returns the number written in --in-base as a number that jco.Parse reads: a number in a base becomes a decimal
+// number with the same sign, and an encoding becomes the hex digits of every byte, so that leading zero bytes count
+// towards the width. Without --in-base, the text is returned as it is
+func (flags *Flags) fromInBase(text string) (string, error) {
+	if flags.inBase == "" {
+		return text, nil
+	}
+	if encoding, ok := ops.LookupEncoding(flags.inBase); ok {
+		bytes, err := encoding.Decode(text)
+		if err != nil {
+			return "", fmt.Errorf("%w '%s' in %s: %v", ops.ErrInvalidNumber, text, encoding.Name, err)
+		}
+		if len(bytes) == 0 {
+			return "0", nil
+		}
+		return ops.BytesToHex(bytes, 0), nil
+	}
+	base, _ := parseBase("in-base", flags.inBase)
+	var bytes []byte
+	var err error
+	if flags.format.Alphabet != "" {
+		bytes, err = ops.AlphabetToBytes(strings.TrimPrefix(text, "-"), flags.format.Alphabet[:base])
+	} else {
+		bytes, err = ops.RadixToBytes(strings.TrimPrefix(text, "-"), base)
+	}
+	if err != nil {
+		return "", err
+	}
+	number := big.NewInt(0).SetBytes(bytes)
+	if strings.HasPrefix(text, "-") {
+		number.Neg(number)
+	}
+	return number.String(), nil
+}
+
+// Parses a number that was written on the command line or in the input with the bit width, and puts the bytes
+// in big-endian order if they were written in little-endian order
+func (flags *Flags) parseNumber(text string) (jco.Value, error) {
+	return flags.parseNumberWithWidth(text, flags.bits)
+}
+
+// Parses a number like parseNumber, but with the given bit width
+func (flags *Flags) parseNumberWithWidth(text string, bits uint) (jco.Value, error) {
+	text, err := flags.fromInBase(text)
+	if err != nil {
+		return jco.Value{}, err
+	}
+	value, err := jco.Parse(text, bits)
+	if err != nil {
+		return jco.Value{}, err
+	}
+	if flags.littleEndian {
+		value = value.ReverseByteorder()
+	}
+	return value, nil
+}
+
+// Parses a number from the command line. It may have its own type like 0x12:u8 or 0xfff0:i16, or its own width like
+// 0x12:8, or with --auto-width get the width that it is written with, and otherwise it has the width from --bits.
+// Reports whether it has its own type
+func (flags *Flags) parseOperand(text string) (jco.Value, bool, error) {
+	// The suffix comes after the closing quote of a character or string literal, which may itself hold colons
+	literalEnd := 0
+	if strings.HasPrefix(text, "'") || strings.HasPrefix(text, `"`) {
+		literalEnd = strings.LastIndexByte(text, text[0])
+	}
+	colon := strings.LastIndex(text[literalEnd:], ":")
+	if colon >= 0 {
+		colon += literalEnd
+	}
+	switch {
+	case colon >= 0:
+		// A plain width like :8 keeps the signedness of the number as written, and is rounded up like --bits
+		suffix := text[colon+1:]
+		widthU64, err := strconv.ParseUint(suffix, 10, 8)
+		if err == nil && widthU64 >= 1 && widthU64 <= 64 {
+			value, err := flags.parseNumberWithWidth(text[:colon], 8*((uint(widthU64)+7)/8))
+			return value, true, err
+		}
+		typ, err := jco.ParseType(suffix)
+		if err != nil || typ.Bits > 64 {
+			return jco.Value{}, false, usageError("invalid type in '%s', expected e.g. :8, :u8 or :i16 with 8 to 64 bits", text)
+		}
+		value, err := flags.parseNumberWithWidth(text[:colon], typ.Bits)
+		if err != nil {
+			return jco.Value{}, false, err
+		}
+		value, _, _ = value.Convert(typ)
+		return value, true, nil
+	case flags.autoWidth:
+		translated, err := flags.fromInBase(text)
+		if err != nil {
+			return jco.Value{}, false, err
+		}
+		bits, err := jco.InferWidth(translated)
+		if err != nil {
+			return jco.Value{}, false, err
+		}
+		if bits > 64 {
+			return jco.Value{}, false, fmt.Errorf("%w: %s is written with %d bits, more than 64", ops.ErrOverflow, text, bits)
+		}
+		value, err := flags.parseNumberWithWidth(text, bits)
+		return value, true, err
+	default:
+		value, err := flags.parseNumber(text)
+		return value, false, err
+	}
+}
+
+// Converts the numbers to the type chosen by --promote, which becomes the bit width, and describes where the type
+// came from in flags.widthNote. The numbers as they were written are kept in flags.operands
+func (flags *Flags) promoteNumbers() error {
+	result := flags.numbers[0].Type()
+	rule := ""
+	switch flags.promote {
+	case "widest":
+		for _, num := range flags.numbers[1:] {
+			result = jco.Widest(result, num.Type())
+		}
+		rule = "the widest number"
+	case "c":
+		result = jco.UsualArithmeticConversion(result, result, 32)
+		for _, num := range flags.numbers[1:] {
+			result = jco.UsualArithmeticConversion(result, num.Type(), 32)
+		}
+		rule = "C's usual arithmetic conversions with 32-bit int"
+	default:
+		typ, err := jco.ParseType(flags.promote)
+		if err != nil || typ.Bits > 64 {
+			return usageError("invalid value for --promote: %s, expected widest, c, or a type like i32", flags.promote)
+		}
+		result = typ
+		rule = "--promote"
+	}
+	flags.resultType = result
+	flags.bits = result.Bits
+	if err := flags.register.CheckWidth(flags.bits); err != nil {
+		return usageError("invalid value for --register: %v", err)
+	}
+
+	sources := []string{}
+	for i, num := range flags.numbers {
+		flags.numbers[i], _, _ = num.Convert(result)
+		sources = append(sources, fmt.Sprintf("%s (%s)", flags.numbersAsWritten[i], num.Type()))
+	}
+	flags.widthNote = fmt.Sprintf("Type: %s, from %s by %s", result, strings.Join(sources, " and "), rule)
+	return nil
 }
@@ -0,0 +1,243 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"github.com/jonathangjertsen/jco-go/expr"
+	"github.com/jonathangjertsen/jco-go/jco"
+	"github.com/jonathangjertsen/jco-go/register"
+	"github.com/jonathangjertsen/jco-go/table"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// JSON-RPC 2.0 error codes
+const (
+	RPC_PARSE_ERROR      = -32700
+	RPC_INVALID_REQUEST  = -32600
+	RPC_METHOD_NOT_FOUND = -32601
+	RPC_INVALID_PARAMS   = -32602
+)
+
+// Longest message accepted by jco rpc
+const RPC_MAX_MESSAGE_LENGTH = 1024 * 1024
+
+// A JSON-RPC request, or a notification if it has no ID
+type rpcRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params"`
+}
+
+// A JSON-RPC response, with either a result or an error
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  *rpcResult      `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+// A JSON-RPC error. For errors in the numbers, the data holds the exit status jco would have exited with
+type rpcError struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+// Parameters of the jco rpc methods. Bits and ops override --bits and --ops for one request
+type rpcParams struct {
+	// Number to convert
+	Text string `json:"text"`
+
+	// Expression to evaluate
+	Expression string `json:"expression"`
+
+	// Register value and fields to decode it with. The fields default to --register
+	Value  string `json:"value"`
+	Fields string `json:"fields"`
+
+	Bits json.Number `json:"bits"`
+	Ops  string      `json:"ops"`
+}
+
+// The result of every jco rpc method: the rows of the table
+type rpcResult struct {
+	Rows []rowRecord `json:"rows"`
+}
+
+// Calls the method and returns its result, or an error to respond with
+func rpcCall(method string, rawParams json.RawMessage, flags *Flags) (*rpcResult, *rpcError) {
+	fill, ok := map[string]func(t *table.Table, params rpcParams, flags *Flags) error{
+		"convert":        rpcConvert,
+		"evaluate":       rpcEvaluate,
+		"decodeRegister": rpcDecodeRegister,
+	}[method]
+	if !ok {
+		return nil, &rpcError{Code: RPC_METHOD_NOT_FOUND, Message: fmt.Sprintf("unknown method '%s'", method)}
+	}
+	params := rpcParams{}
+	if len(rawParams) > 0 {
+		if err := json.Unmarshal(rawParams, &params); err != nil {
+			return nil, &rpcError{Code: RPC_INVALID_PARAMS, Message: err.Error()}
+		}
+	}
+	requestFlags, err := flags.withOverrides(params.Bits.String(), params.Ops)
+	if err == nil {
+		t := newTable(requestFlags)
+		if err = fill(t, params, requestFlags); err == nil {
+			return &rpcResult{Rows: rowRecords(t, requestFlags.format)}, nil
+		}
+	}
+	data := map[string]int{"exitStatus": ExitCode(err)}
+	return nil, &rpcError{Code: RPC_INVALID_PARAMS, Message: err.Error(), Data: data}
+}
+
+// Fills in the table for the number in params.Text, like jco <number>
+func rpcConvert(t *table.Table, params rpcParams, flags *Flags) error {
+	value, err := flags.parseNumber(params.Text)
+	if err != nil {
+		return err
+	}
+	t.One(value, params.Text)
+	return nil
+}
+
+// Fills in the table with the fields of the register value in params.Value
+func rpcDecodeRegister(t *table.Table, params rpcParams, flags *Flags) error {
+	definition := flags.register
+	if params.Fields != "" {
+		loaded, err := register.Load(params.Fields)
+		if err != nil {
+			return usageError("invalid fields: %v", err)
+		}
+		definition = loaded
+	}
+	if definition == nil {
+		return usageError("no fields given, and --register is not set")
+	}
+	value, err := flags.parseNumber(params.Value)
+	if err != nil {
+		return err
+	}
+	if err := definition.CheckWidth(value.Bits()); err != nil {
+		return usageError("invalid fields: %v", err)
+	}
+	t.Fields(value, definition)
+	return nil
+}
+
+// Fills in the table for the expression in params.Expression
+func rpcEvaluate(t *table.Table, params rpcParams, flags *Flags) error {
+	result, err := expr.Evaluate(params.Expression, flags.bits/8)
+	if err != nil {
+		return err
+	}
+	value, err := jco.New(result, flags.bits)
+	if err != nil {
+		return err
+	}
+	t.One(value, "("+params.Expression+")")
+	return nil
+}
+
+// Handles one message and returns the response, or nil if there should be none (for notifications)
+func rpcHandle(message []byte, flags *Flags) *rpcResponse {
+	request := rpcRequest{}
+	if err := json.Unmarshal(message, &request); err != nil {
+		return &rpcResponse{JSONRPC: "2.0", ID: json.RawMessage("null"), Error: &rpcError{Code: RPC_PARSE_ERROR, Message: err.Error()}}
+	}
+	if request.JSONRPC != "2.0" || request.Method == "" {
+		id := request.ID
+		if len(id) == 0 {
+			id = json.RawMessage("null")
+		}
+		return &rpcResponse{JSONRPC: "2.0", ID: id, Error: &rpcError{Code: RPC_INVALID_REQUEST, Message: "expected a JSON-RPC 2.0 request"}}
+	}
+	result, err := rpcCall(request.Method, request.Params, flags)
+	if len(request.ID) == 0 {
+		return nil
+	}
+	return &rpcResponse{JSONRPC: "2.0", ID: request.ID, Result: result, Error: err}
+}
+
+// Reads the next message, which is either a line of JSON or has LSP-style Content-Length headers.
+// Returns the message, whether it had headers, and io.EOF when the input ends
+func rpcRead(reader *bufio.Reader) ([]byte, bool, error) {
+	for {
+		line, err := rpcReadLine(reader)
+		if err != nil && (err != io.EOF || strings.TrimSpace(line) == "") {
+			return nil, false, err
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if !strings.HasPrefix(strings.ToLower(line), "content-length:") {
+			return []byte(line), false, nil
+		}
+
+		// Skip any other headers up to the blank line, then read exactly the length
+		length, parseErr := strconv.Atoi(strings.TrimSpace(line[len("content-length:"):]))
+		if parseErr != nil || length < 0 || length > RPC_MAX_MESSAGE_LENGTH {
+			return nil, true, fmt.Errorf("invalid header '%s'", line)
+		}
+		for {
+			header, err := rpcReadLine(reader)
+			if err != nil {
+				return nil, true, err
+			}
+			if strings.TrimSpace(header) == "" {
+				break
+			}
+		}
+		message := make([]byte, length)
+		_, err = io.ReadFull(reader, message)
+		return message, true, err
+	}
+}
+
+// Reads a line like ReadString('\n') does, but stops with an error as soon as the line is longer than
+// RPC_MAX_MESSAGE_LENGTH, so that a line that never ends does not take up more and more memory
+func rpcReadLine(reader *bufio.Reader) (string, error) {
+	var line []byte
+	for {
+		piece, err := reader.ReadSlice('\n')
+		if len(line)+len(piece) > RPC_MAX_MESSAGE_LENGTH {
+			return "", fmt.Errorf("message is longer than %d bytes", RPC_MAX_MESSAGE_LENGTH)
+		}
+		line = append(line, piece...)
+		if err != bufio.ErrBufferFull {
+			return string(line), err
+		}
+	}
+}
+
+// Answers JSON-RPC 2.0 requests on stdin until it ends. Messages are either one JSON object per line, or framed with
+// Content-Length headers like in the Language Server Protocol, and each response is framed like its request
+func RunRPC(flags *Flags) error {
+	reader := bufio.NewReader(flags.streams.In)
+	writer := bufio.NewWriter(flags.streams.Out)
+	for {
+		message, framed, err := rpcRead(reader)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		response := rpcHandle(message, flags)
+		if response == nil {
+			continue
+		}
+		encoded, _ := json.Marshal(response)
+		if framed {
+			fmt.Fprintf(writer, "Content-Length: %d\r\n\r\n%s", len(encoded), encoded)
+		} else {
+			fmt.Fprintf(writer, "%s\n", encoded)
+		}
+		writer.Flush()
+	}
+}
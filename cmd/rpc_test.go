@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+// A reader that never ends and never has a line break
+type endlessReader struct{}
+
+func TestRPC(t *testing.T) {
+	input := strings.Join([]string{
+		`{"jsonrpc": "2.0", "id": 1, "method": "convert", "params": {"text": "0x1877", "bits": 16, "ops": "value,popcount"}}`,
+		`{"jsonrpc": "2.0", "id": 2, "method": "evaluate", "params": {"expression": "1 + 2 << 3", "bits": 8, "ops": "value"}}`,
+		`{"jsonrpc": "2.0", "id": 3, "method": "decodeRegister", "params": {"value": "0x1877", "fields": "EN[0],MODE[3:1]"}}`,
+		`{"jsonrpc": "2.0", "method": "convert", "params": {"text": "1"}}`,
+		`{"jsonrpc": "2.0", "id": 4, "method": "convert", "params": {"text": "0x1ff", "bits": 8}}`,
+		`{"jsonrpc": "2.0", "id": 5, "method": "hover"}`,
+		`{"jsonrpc": "2.0", "id": 6, "method": "convert", "params": {"text": 5}}`,
+		`not json`,
+	}, "\n")
+	framedRequest := `{"jsonrpc": "2.0", "id": 7, "method": "convert", "params": {"text": "0x12", "ops": "value"}}`
+	input += fmt.Sprintf("\nContent-Length: %d\r\n\r\n%s", len(framedRequest), framedRequest)
+	var stdout bytes.Buffer
+	isolateConfig(t)
+	flags, err := parseFlags([]string{"--bits", "32"}, Streams{strings.NewReader(input), &stdout, os.Stderr})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := RunRPC(flags); err != nil {
+		t.Fatal(err)
+	}
+
+	// The framed response comes last, after one line for each request that is not a notification
+	output := stdout.String()
+	framed := strings.Index(output, "Content-Length: ")
+	if framed < 0 {
+		t.Fatalf("Want a framed response, have\n%s", output)
+	}
+	lines := strings.Split(strings.TrimSpace(output[:framed]), "\n")
+	lines = append(lines, output[strings.Index(output, "\r\n\r\n")+4:])
+	var want = []struct {
+		id    string
+		hex   []string
+		error int
+	}{
+		{"1", []string{"0x1877", "0x0008"}, 0},
+		{"2", []string{"0x18"}, 0},
+		{"3", []string{"0x00000001", "0x00000003"}, 0},
+		{"4", nil, RPC_INVALID_PARAMS},
+		{"5", nil, RPC_METHOD_NOT_FOUND},
+		{"6", nil, RPC_INVALID_PARAMS},
+		{"null", nil, RPC_PARSE_ERROR},
+		{"7", []string{"0x00000012"}, 0},
+	}
+	if len(lines) != len(want) {
+		t.Fatalf("Want %d responses, have %d:\n%s", len(want), len(lines), output)
+	}
+	for i, line := range lines {
+		var response struct {
+			ID     json.RawMessage `json:"id"`
+			Result *rpcResult      `json:"result"`
+			Error  *rpcError       `json:"error"`
+		}
+		if err := json.Unmarshal([]byte(line), &response); err != nil {
+			t.Fatalf("Response %d: %v\n%s", i, err, line)
+		}
+		var members map[string]json.RawMessage
+		json.Unmarshal([]byte(line), &members)
+		if _, hasResult := members["result"]; hasResult == (want[i].error != 0) {
+			t.Errorf("Response %d: want exactly one of result and error, have %s", i, line)
+		}
+		if string(response.ID) != want[i].id {
+			t.Errorf("Response %d: want id %s, have %s", i, want[i].id, response.ID)
+		}
+		if want[i].error != 0 {
+			if response.Error == nil || response.Error.Code != want[i].error {
+				t.Errorf("Response %d: want error %d, have %s", i, want[i].error, line)
+			}
+			continue
+		}
+		if response.Result == nil || len(response.Result.Rows) != len(want[i].hex) {
+			t.Errorf("Response %d: want %d rows, have %s", i, len(want[i].hex), line)
+			continue
+		}
+		for j, row := range response.Result.Rows {
+			if row.Hex != want[i].hex[j] {
+				t.Errorf("Response %d row %d: want %s, have %s", i, j, want[i].hex[j], row.Hex)
+			}
+		}
+	}
+}
+
+func TestRPCLongMessage(t *testing.T) {
+	isolateConfig(t)
+	for name, input := range map[string]io.Reader{
+		"line":   endlessReader{},
+		"header": io.MultiReader(strings.NewReader("Content-Length: 2\r\n"), endlessReader{}),
+	} {
+		t.Run(name, func(t *testing.T) {
+			flags, err := parseFlags(nil, Streams{input, &bytes.Buffer{}, os.Stderr})
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = RunRPC(flags)
+			if err == nil || !strings.Contains(err.Error(), "longer than") {
+				t.Errorf("Want an error for a message that is too long, have %v", err)
+			}
+		})
+	}
+}
+
+func (endlessReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 'x'
+	}
+	return len(p), nil
+}
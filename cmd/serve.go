@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"github.com/jonathangjertsen/jco-go/expr"
+	"github.com/jonathangjertsen/jco-go/jco"
+	"github.com/jonathangjertsen/jco-go/table"
+	"net/http"
+	"time"
+)
+
+const (
+	// Longest expression accepted by /api/eval
+	SERVE_MAX_EXPRESSION_LENGTH = 4096
+
+	// Time allowed for reading the headers of a request
+	SERVE_READ_HEADER_TIMEOUT = 10 * time.Second
+)
+
+//go:embed web/index.html
+var servePage []byte
+
+// The response to an API request: the rows of the table, or why they could not be computed
+type serveResponse struct {
+	Rows  []rowRecord `json:"rows"`
+	Error string      `json:"error,omitempty"`
+}
+
+// Returns the handler for all the pages and API endpoints of jco serve
+func serveHandler(flags *Flags) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(servePage)
+	})
+	mux.HandleFunc("/api/one", serveTable(flags, func(t *table.Table, r *http.Request, flags *Flags) error {
+		x := r.URL.Query().Get("x")
+		value, err := flags.parseNumber(x)
+		if err != nil {
+			return err
+		}
+		t.One(value, x)
+		if flags.register != nil {
+			t.Fields(value, flags.register)
+		}
+		return nil
+	}))
+	mux.HandleFunc("/api/two", serveTable(flags, func(t *table.Table, r *http.Request, flags *Flags) error {
+		a, b := r.URL.Query().Get("a"), r.URL.Query().Get("b")
+		valueA, err := flags.parseNumber(a)
+		if err != nil {
+			return err
+		}
+		valueB, err := flags.parseNumber(b)
+		if err != nil {
+			return err
+		}
+		t.Two(valueA, valueB, a, b)
+		return nil
+	}))
+	mux.HandleFunc("/api/eval", serveTable(flags, func(t *table.Table, r *http.Request, flags *Flags) error {
+		expression := r.URL.Query().Get("expr")
+		if len(expression) > SERVE_MAX_EXPRESSION_LENGTH {
+			return usageError("expression is longer than %d characters", SERVE_MAX_EXPRESSION_LENGTH)
+		}
+		result, err := expr.Evaluate(expression, flags.bits/8)
+		if err != nil {
+			return err
+		}
+		value, err := jco.New(result, flags.bits)
+		if err != nil {
+			return err
+		}
+		t.One(value, "("+expression+")")
+		return nil
+	}))
+	return mux
+}
+
+// Returns a handler which fills in a table for the request and responds with its rows as JSON
+func serveTable(flags *Flags, fill func(t *table.Table, r *http.Request, flags *Flags) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			json.NewEncoder(w).Encode(serveResponse{Error: "only GET is supported"})
+			return
+		}
+		requestFlags, err := flags.withOverrides(r.URL.Query().Get("bits"), r.URL.Query().Get("ops"))
+		var t *table.Table
+		if err == nil {
+			t = newTable(requestFlags)
+			err = fill(t, r, requestFlags)
+		}
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(serveResponse{Error: err.Error()})
+			return
+		}
+		json.NewEncoder(w).Encode(serveResponse{Rows: rowRecords(t, requestFlags.format)})
+	}
+}
+
+// Serves the analyses over HTTP until the server fails
+func RunServe(flags *Flags) error {
+	server := &http.Server{
+		Addr:              flags.addr,
+		Handler:           serveHandler(flags),
+		ReadHeaderTimeout: SERVE_READ_HEADER_TIMEOUT,
+	}
+	fmt.Fprintf(flags.streams.Err, "jco: serving on http://%s/\n", flags.addr)
+	return server.ListenAndServe()
+}
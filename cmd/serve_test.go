@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestServe(t *testing.T) {
+	isolateConfig(t)
+	flags, err := parseFlags([]string{"--bits", "32"}, Streams{os.Stdin, os.Stdout, os.Stderr})
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(serveHandler(flags))
+	defer server.Close()
+
+	var vector = []struct {
+		path    string
+		status  int
+		formula string
+		hex     string
+		error   string
+	}{
+		{"/api/one?x=0x1877&bits=16", http.StatusOK, "0x1877 ", "0x1877", ""},
+		{"/api/one?x=0x1877", http.StatusOK, "0x1877 ", "0x00001877", ""},
+		{"/api/one?x=0x1877&bits=16&ops=popcount", http.StatusOK, "popcount(0x1877)", "0x0008", ""},
+		{"/api/two?a=0x12&b=0x34&bits=8&ops=add", http.StatusOK, "0x12  + 0x34", "0x46", ""},
+		{"/api/eval?expr=" + "1%20%2B%202%20%3C%3C%203&bits=8", http.StatusOK, "(1 + 2 << 3) ", "0x18", ""},
+		{"/api/one?x=popcnt", http.StatusBadRequest, "", "", "invalid number 'popcnt'"},
+		{"/api/one?x=0x1ff&bits=8", http.StatusBadRequest, "", "", "overflow"},
+		{"/api/one?x=1&bits=65", http.StatusBadRequest, "", "", "invalid value for bits: 65"},
+		{"/api/one?x=1&ops=popcnt", http.StatusBadRequest, "", "", "popcnt"},
+	}
+	for _, tt := range vector {
+		t.Run(tt.path, func(t *testing.T) {
+			response, err := http.Get(server.URL + tt.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer response.Body.Close()
+			var result serveResponse
+			if err := json.NewDecoder(response.Body).Decode(&result); err != nil {
+				t.Fatal(err)
+			}
+			if response.StatusCode != tt.status {
+				t.Errorf("Want status %d, have %d (%s)", tt.status, response.StatusCode, result.Error)
+			}
+			if tt.error != "" && !strings.Contains(result.Error, tt.error) {
+				t.Errorf("Want an error containing %q, have %q", tt.error, result.Error)
+			}
+			if tt.formula != "" && (len(result.Rows) == 0 || result.Rows[0].Formula != tt.formula || result.Rows[0].Hex != tt.hex) {
+				t.Errorf("Want first row %q = %s, have %+v", tt.formula, tt.hex, result.Rows)
+			}
+		})
+	}
+
+	// The page is served on / and nothing else
+	response, err := http.Get(server.URL + "/")
+	if err != nil || response.StatusCode != http.StatusOK || !strings.HasPrefix(response.Header.Get("Content-Type"), "text/html") {
+		t.Errorf("Want the page on /, have %v %v", response, err)
+	}
+	response, err = http.Get(server.URL + "/nothing")
+	if err != nil || response.StatusCode != http.StatusNotFound {
+		t.Errorf("Want 404 for /nothing, have %v %v", response, err)
+	}
+}
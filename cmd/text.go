@@ -0,0 +1,21 @@
+package cmd
+
+import (
+	"github.com/jonathangjertsen/jco-go/table"
+)
+
+// Shows the bytes of the numbers as text, and the numbers as Unicode codepoints with their encodings
+func RunText(flags *Flags) error {
+	if flags.batch != "" || flags.follow || flags.register != nil || len(flags.widths) > 0 || flags.bytes {
+		return usageError("--text can not be combined with --batch, --follow, --register, --widths or --bytes")
+	}
+	if len(flags.numbers) == 0 {
+		return usageError("expected at least 1 number")
+	}
+	t := table.NewText()
+	t.SetFormat(flags.format)
+	for i, number := range flags.numbers {
+		t.One(number, flags.numbersAsWritten[i])
+	}
+	return t.RenderTo(flags.streams.Out)
+}
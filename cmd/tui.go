@@ -0,0 +1,190 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/jonathangjertsen/jco-go/jco"
+	"golang.org/x/term"
+	"os"
+	"strings"
+)
+
+// Escape codes used to take over the terminal in jco tui
+const (
+	TUI_ENTER_SCREEN = "\x1b[?1049h\x1b[?25l"
+	TUI_LEAVE_SCREEN = "\x1b[?25h\x1b[?1049l"
+	TUI_CLEAR        = "\x1b[H\x1b[2J"
+	TUI_REVERSE      = "\x1b[7m"
+	TUI_RESET        = "\x1b[0m"
+)
+
+// Keys that jco tui responds to, as decoded from the terminal input
+const (
+	TUI_KEY_LEFT   = "left"
+	TUI_KEY_RIGHT  = "right"
+	TUI_KEY_UP     = "up"
+	TUI_KEY_DOWN   = "down"
+	TUI_KEY_TOGGLE = " "
+	TUI_KEY_CLEAR  = "c"
+	TUI_KEY_INVERT = "~"
+	TUI_KEY_QUIT   = "q"
+)
+
+// State of the bit editor
+type tuiState struct {
+	value jco.Value
+
+	// Index of the bit under the cursor, where 0 is the least significant bit
+	cursor uint
+}
+
+// Returns the column where bit i is drawn, with a space between every nibble
+func tuiColumn(bit uint, bits uint) int {
+	fromLeft := int(bits - 1 - bit)
+	return fromLeft + fromLeft/4
+}
+
+// Splits terminal input into keys, decoding the escape sequences for the arrow keys
+func tuiKeys(input []byte) []string {
+	arrows := map[byte]string{'A': TUI_KEY_UP, 'B': TUI_KEY_DOWN, 'C': TUI_KEY_RIGHT, 'D': TUI_KEY_LEFT}
+	keys := []string{}
+	for i := 0; i < len(input); i++ {
+		switch {
+		case input[i] == 0x1b && i+2 < len(input) && (input[i+1] == '[' || input[i+1] == 'O'):
+			if key, ok := arrows[input[i+2]]; ok {
+				keys = append(keys, key)
+			}
+			i += 2
+		case input[i] == 0x1b || input[i] == 0x03 || input[i] == 0x04:
+			// Escape, Ctrl-C and Ctrl-D
+			keys = append(keys, TUI_KEY_QUIT)
+		case input[i] == 'h':
+			keys = append(keys, TUI_KEY_LEFT)
+		case input[i] == 'l':
+			keys = append(keys, TUI_KEY_RIGHT)
+		case input[i] == 'k':
+			keys = append(keys, TUI_KEY_UP)
+		case input[i] == 'j':
+			keys = append(keys, TUI_KEY_DOWN)
+		default:
+			keys = append(keys, string(input[i]))
+		}
+	}
+	return keys
+}
+
+// Opens a full-screen bit editor on the terminal, starting with the given value, and prints the final value when
+// it quits
+func RunTUI(value jco.Value, flags *Flags) error {
+	if !isTerminal(flags.streams.In) || !isTerminal(flags.streams.Out) {
+		return usageError("tui needs a terminal")
+	}
+	// Raw mode delivers every key as soon as it is pressed, without echoing it
+	fd := int(flags.streams.In.(*os.File).Fd())
+	saved, err := term.MakeRaw(fd)
+	if err != nil {
+		return fmt.Errorf("could not set up the terminal: %v", err)
+	}
+	fmt.Fprint(flags.streams.Out, TUI_ENTER_SCREEN)
+
+	state := tuiState{value: value}
+	input := make([]byte, 64)
+	running := true
+	for running {
+		// The terminal is in raw mode, so every line needs a carriage return
+		screen := strings.ReplaceAll(state.render(flags), "\n", "\r\n")
+		fmt.Fprint(flags.streams.Out, TUI_CLEAR+screen)
+		n, err := flags.streams.In.Read(input)
+		if err != nil {
+			break
+		}
+		for _, key := range tuiKeys(input[:n]) {
+			running = running && state.handle(key)
+		}
+	}
+	fmt.Fprint(flags.streams.Out, TUI_LEAVE_SCREEN)
+	if err := term.Restore(fd, saved); err != nil {
+		return fmt.Errorf("could not restore the terminal: %v", err)
+	}
+	fmt.Fprintln(flags.streams.Out, state.value.Hex())
+	return nil
+}
+
+// Updates the state for the key, and returns false if the editor should quit
+func (s *tuiState) handle(key string) bool {
+	bits := s.value.Bits()
+	switch key {
+	case TUI_KEY_LEFT:
+		s.cursor = (s.cursor + 1) % bits
+	case TUI_KEY_RIGHT:
+		s.cursor = (s.cursor + bits - 1) % bits
+	case TUI_KEY_UP:
+		s.cursor = (s.cursor + 4) % bits
+	case TUI_KEY_DOWN:
+		s.cursor = (s.cursor + bits - 4) % bits
+	case TUI_KEY_TOGGLE:
+		mask, _ := jco.FromUint64(1<<s.cursor, bits)
+		s.value = s.value.Xor(mask)
+	case TUI_KEY_CLEAR:
+		s.value = s.value.Xor(s.value)
+	case TUI_KEY_INVERT:
+		s.value = s.value.Not()
+	case TUI_KEY_QUIT:
+		return false
+	}
+	return true
+}
+
+// Returns the screen for the state, with the bits, the register fields and the table
+func (s *tuiState) render(flags *Flags) string {
+	bits := s.value.Bits()
+	width := tuiColumn(0, bits) + 1
+	var builder strings.Builder
+	builder.WriteString("jco tui: arrows or hjkl move, space toggles, c clears, ~ inverts, q quits\n\n")
+
+	// Index of the most significant bit of each nibble, above the nibble
+	indices := []byte(strings.Repeat(" ", width+2))
+	for bit := int(bits) - 1; bit >= 0; bit -= 4 {
+		copy(indices[tuiColumn(uint(bit), bits):], fmt.Sprint(bit))
+	}
+	builder.WriteString(strings.TrimRight(string(indices), " ") + "\n")
+
+	// The bits, with the one under the cursor highlighted
+	digits := s.value.Bin()[len("0b"):]
+	for i := range digits {
+		bit := bits - 1 - uint(i)
+		if i > 0 && i%4 == 0 {
+			builder.WriteString(" ")
+		}
+		if bit == s.cursor {
+			builder.WriteString(TUI_REVERSE + digits[i:i+1] + TUI_RESET)
+		} else {
+			builder.WriteString(digits[i : i+1])
+		}
+	}
+	builder.WriteString("\n")
+
+	// A line under the bits of each register field, labelled with the name and value of the field
+	t := newTable(flags)
+	if flags.register != nil {
+		t.Fields(s.value, flags.register)
+	}
+	for i, row := range t.Rows() {
+		field := flags.register[i]
+		line := []byte(strings.Repeat(" ", width))
+		for bit := field.Lsb; bit <= field.Msb && bit < bits; bit++ {
+			line[tuiColumn(bit, bits)] = '-'
+		}
+		if field.Msb < bits {
+			line[tuiColumn(field.Msb, bits)] = '|'
+		}
+		if field.Lsb < bits {
+			line[tuiColumn(field.Lsb, bits)] = '|'
+		}
+		builder.WriteString(fmt.Sprintf("%s  %s = %s\n", line, row.Formula, row.Value.AsUnsigned().Dec()))
+	}
+	builder.WriteString(fmt.Sprintf("\nbit %d under the cursor\n\n", s.cursor))
+
+	t.One(s.value, "x")
+	builder.WriteString(t.String())
+	return builder.String()
+}
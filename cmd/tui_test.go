@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"github.com/jonathangjertsen/jco-go/jco"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTUIKeys(t *testing.T) {
+	have := tuiKeys([]byte("\x1b[D\x1b[Ch \x1bOAjq\x03"))
+	want := []string{TUI_KEY_LEFT, TUI_KEY_RIGHT, TUI_KEY_LEFT, TUI_KEY_TOGGLE, TUI_KEY_UP, TUI_KEY_DOWN, TUI_KEY_QUIT, TUI_KEY_QUIT}
+	if !reflect.DeepEqual(have, want) {
+		t.Errorf("Want %v, have %v", want, have)
+	}
+}
+
+func TestTUIRender(t *testing.T) {
+	isolateConfig(t)
+	flags, err := parseFlags([]string{"-b", "16", "--register", "EN[0],MODE[3:1]", "--color", "never"}, Streams{os.Stdin, os.Stdout, os.Stderr})
+	if err != nil {
+		t.Fatal(err)
+	}
+	value, _ := jco.Parse("0x1877", 16)
+	state := tuiState{value: value, cursor: 3}
+	lines := strings.Split(state.render(flags), "\n")
+	want := []string{
+		"15   11   7    3",
+		"0001 1000 0111 " + TUI_REVERSE + "0" + TUI_RESET + "111",
+		"                  |  EN[0] = 1",
+		"               |-|   MODE[3:1] = 3",
+	}
+	if !reflect.DeepEqual(lines[2:6], want) {
+		t.Errorf("Want\n%s\nhave\n%s", strings.Join(want, "\n"), strings.Join(lines[2:6], "\n"))
+	}
+	if !strings.Contains(state.render(flags), "popcount(x)") {
+		t.Errorf("The table is missing")
+	}
+}
+
+func TestTUIState(t *testing.T) {
+	value, _ := jco.Parse("0x1877", 16)
+	state := tuiState{value: value}
+	for _, key := range []string{TUI_KEY_TOGGLE, TUI_KEY_LEFT, TUI_KEY_TOGGLE, TUI_KEY_UP, TUI_KEY_TOGGLE, TUI_KEY_RIGHT, TUI_KEY_RIGHT, TUI_KEY_RIGHT} {
+		if !state.handle(key) {
+			t.Fatalf("Quit on %q", key)
+		}
+	}
+	// Toggled bits 0, 1 and 5, then moved right from bit 5
+	if state.value.Hex() != "0x1854" || state.cursor != 2 {
+		t.Errorf("Want 0x1854 with the cursor on bit 2, have %s with the cursor on bit %d", state.value.Hex(), state.cursor)
+	}
+
+	// The cursor wraps around at both ends
+	state.cursor = 0
+	state.handle(TUI_KEY_RIGHT)
+	if state.cursor != 15 {
+		t.Errorf("Want the cursor to wrap to bit 15, have bit %d", state.cursor)
+	}
+	state.handle(TUI_KEY_UP)
+	if state.cursor != 3 {
+		t.Errorf("Want the cursor to wrap to bit 3, have bit %d", state.cursor)
+	}
+	if state.handle(TUI_KEY_QUIT) {
+		t.Errorf("Did not quit")
+	}
+}
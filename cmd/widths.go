@@ -0,0 +1,25 @@
+package cmd
+
+import (
+	"github.com/jonathangjertsen/jco-go/table"
+)
+
+// Shows the analysis of one or two numbers at each of the widths in --widths, side by side
+func RunWidths(flags *Flags) error {
+	if flags.batch != "" || flags.follow || flags.register != nil {
+		return usageError("--widths can not be combined with --batch, --follow or --register")
+	}
+	t := table.NewWidths(flags.widths)
+	t.SetFormat(flags.format)
+	t.SetOperations(flags.operations)
+	t.SetColumns(flags.columns)
+	switch len(flags.numbers) {
+	case 1:
+		t.One(flags.numbers[0], flags.numbersAsWritten[0])
+	case 2:
+		t.Two(flags.numbers[0], flags.numbers[1], flags.numbersAsWritten[0], flags.numbersAsWritten[1])
+	default:
+		return usageError("expected 1 or 2 numbers, got %d", len(flags.numbers))
+	}
+	return t.RenderTo(flags.streams.Out)
+}
@@ -0,0 +1,179 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Name of the project-local config file, which is looked for in the current directory and its parents
+const LOCAL_FILE_NAME = ".jco"
+
+// Settings read from a config file. Values are kept as the strings that would be given on the command line
+type Config struct {
+	// Settings that apply unless a profile overrides them
+	Defaults map[string]string
+
+	// Named bundles of settings, from [profile.<name>] sections
+	Profiles map[string]map[string]string
+}
+
+// Parses a TOML string, integer or boolean into the string that would be given on the command line
+func parseValue(value string) (string, error) {
+	switch {
+	case len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"':
+		return strconv.Unquote(value)
+	case len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'':
+		return value[1 : len(value)-1], nil
+	case value == "true" || value == "false":
+		return value, nil
+	}
+	if _, err := strconv.ParseInt(strings.ReplaceAll(value, "_", ""), 0, 64); err == nil {
+		return strings.ReplaceAll(value, "_", ""), nil
+	}
+	return "", fmt.Errorf("invalid value %s, expected a quoted string, an integer, true or false", value)
+}
+
+// Returns the line without any comment, leaving # inside strings alone
+func stripComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0 && c == '\\' && quote == '"':
+			i++
+		case quote != 0 && c == quote:
+			quote = 0
+		case quote == 0 && (c == '"' || c == '\''):
+			quote = c
+		case quote == 0 && c == '#':
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// Returns the path of the user's config file, which may not exist
+func GlobalPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "jco", "config.toml"), nil
+}
+
+// Reads and merges the user's config file and the nearest project-local config file, if they exist.
+// Settings in the project-local file take precedence
+func Load() (*Config, error) {
+	merged := New()
+	paths := []string{}
+	if global, err := GlobalPath(); err == nil {
+		paths = append(paths, global)
+	}
+	if local, ok := LocalPath(); ok {
+		paths = append(paths, local)
+	}
+	for _, path := range paths {
+		contents, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		parsed, err := Parse(string(contents))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+		merged.Merge(parsed)
+	}
+	return merged, nil
+}
+
+// Returns the path of the project-local config file in the current directory or the nearest parent
+func LocalPath() (string, bool) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", false
+	}
+	for {
+		path := filepath.Join(dir, LOCAL_FILE_NAME)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// Returns an empty config
+func New() *Config {
+	return &Config{
+		Defaults: map[string]string{},
+		Profiles: map[string]map[string]string{},
+	}
+}
+
+// Parses the subset of TOML that jco needs: key = value pairs where the value is a string, an integer or a
+// boolean, and [profile.<name>] sections. Anything after a # outside a string is a comment
+func Parse(text string) (*Config, error) {
+	config := New()
+	section := config.Defaults
+	for i, line := range strings.Split(text, "\n") {
+		lineNumber := i + 1
+		line = strings.TrimSpace(stripComment(line))
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") {
+				return nil, fmt.Errorf("line %d: unterminated section header", lineNumber)
+			}
+			name := strings.TrimSpace(line[1 : len(line)-1])
+			if !strings.HasPrefix(name, "profile.") || len(name) == len("profile.") {
+				return nil, fmt.Errorf("line %d: unknown section [%s], expected [profile.<name>]", lineNumber, name)
+			}
+			profile := strings.Trim(name[len("profile."):], `"'`)
+			if _, exists := config.Profiles[profile]; !exists {
+				config.Profiles[profile] = map[string]string{}
+			}
+			section = config.Profiles[profile]
+			continue
+		}
+
+		equals := strings.Index(line, "=")
+		if equals < 0 {
+			return nil, fmt.Errorf("line %d: expected key = value", lineNumber)
+		}
+		key := strings.TrimSpace(line[:equals])
+		value, err := parseValue(strings.TrimSpace(line[equals+1:]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", lineNumber, err)
+		}
+		if key == "" {
+			return nil, fmt.Errorf("line %d: missing key", lineNumber)
+		}
+		section[key] = value
+	}
+	return config, nil
+}
+
+// Adds the settings from the other config, overriding any settings that are in both
+func (c *Config) Merge(other *Config) {
+	for key, value := range other.Defaults {
+		c.Defaults[key] = value
+	}
+	for name, profile := range other.Profiles {
+		if _, exists := c.Profiles[name]; !exists {
+			c.Profiles[name] = map[string]string{}
+		}
+		for key, value := range profile {
+			c.Profiles[name][key] = value
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	merged := New()
+	merged.Merge(&Config{map[string]string{"bits": "16", "group": "true"}, map[string]map[string]string{"avr": {"bits": "8"}}})
+	merged.Merge(&Config{map[string]string{"bits": "32"}, map[string]map[string]string{"avr": {"ruler": "true"}}})
+	want := &Config{
+		map[string]string{"bits": "32", "group": "true"},
+		map[string]map[string]string{"avr": {"bits": "8", "ruler": "true"}},
+	}
+	if !reflect.DeepEqual(merged, want) {
+		t.Errorf("Want %v, have %v\n", want, merged)
+	}
+}
+
+func TestParse(t *testing.T) {
+	have, err := Parse(`
+# Defaults for everyone
+bits = 16
+group = true
+group-sep = "'" # a comment
+columns = 'dec,hex'
+
+[profile.cortex-m]
+bits = 32
+pattern = "REG=(0x[0-9a-f]+) # not a comment"
+
+[profile."avr"]
+bits = 8
+`)
+	want := &Config{
+		Defaults: map[string]string{
+			"bits":      "16",
+			"group":     "true",
+			"group-sep": "'",
+			"columns":   "dec,hex",
+		},
+		Profiles: map[string]map[string]string{
+			"cortex-m": {"bits": "32", "pattern": "REG=(0x[0-9a-f]+) # not a comment"},
+			"avr":      {"bits": "8"},
+		},
+	}
+	if err != nil || !reflect.DeepEqual(have, want) {
+		t.Errorf("Want %v, have %v (error %v)\n", want, have, err)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, text := range []string{
+		"bits",
+		"bits = sixteen",
+		"[profile.avr",
+		"[settings]",
+		"[profile.]",
+		"= 5",
+	} {
+		t.Run(text, func(t *testing.T) {
+			if have, err := Parse(text); err == nil {
+				t.Errorf("Want error, have %v\n", have)
+			}
+		})
+	}
+}
@@ -0,0 +1,218 @@
+package expr
+
+import (
+	"fmt"
+	"github.com/jonathangjertsen/jco-go/ops"
+	"strings"
+)
+
+// Binding strength of the infix operators, following C
+var PRECEDENCE = map[string]int{
+	"|":  1,
+	"^":  2,
+	"&":  3,
+	"<<": 4,
+	">>": 4,
+	"+":  5,
+	"-":  5,
+}
+
+type parser struct {
+	tokens []string
+	pos    int
+	nBytes uint
+}
+
+// Returns whether the character can be part of a number or a name
+func isWordChar(c byte) bool {
+	return c == '_' || ('0' <= c && c <= '9') || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
+
+// Splits the expression into numbers, names, operators and parentheses
+func tokenize(expression string) ([]string, error) {
+	tokens := []string{}
+	for i := 0; i < len(expression); {
+		c := expression[i]
+		switch {
+		case c == ' ' || c == '\t':
+			i++
+		case c == '\'' || c == '"':
+			// A character or string literal runs to the next unescaped quote of the same kind
+			start := i
+			for i++; i < len(expression) && expression[i] != c; i++ {
+				if expression[i] == '\\' {
+					i++
+				}
+			}
+			if i >= len(expression) {
+				return nil, fmt.Errorf("unterminated %c at position %d", c, start)
+			}
+			i++
+			tokens = append(tokens, expression[start:i])
+		case ops.IsTextLiteral(expression[i:]) && i+2 < len(expression) && isWordChar(expression[i+2]):
+			// A codepoint like U+1F600, which would otherwise be read as an addition
+			start := i
+			for i += 2; i < len(expression) && isWordChar(expression[i]); i++ {
+			}
+			tokens = append(tokens, expression[start:i])
+		case isWordChar(c):
+			start := i
+			for i < len(expression) && isWordChar(expression[i]) {
+				i++
+			}
+			tokens = append(tokens, expression[start:i])
+		case strings.HasPrefix(expression[i:], "<<") || strings.HasPrefix(expression[i:], ">>"):
+			tokens = append(tokens, expression[i:i+2])
+			i += 2
+		case strings.IndexByte("+-|&^~(),", c) >= 0:
+			tokens = append(tokens, expression[i:i+1])
+			i++
+		default:
+			return nil, fmt.Errorf("unexpected character '%c' at position %d", c, i)
+		}
+	}
+	return tokens, nil
+}
+
+// Evaluates an expression such as "popcount(0x1877 & ~0xff) << 2" made up of numbers, registered
+// operations called by name, and the registered operator symbols. Every number and intermediate result is
+// fitted to nBytes, so that e.g. ~ and - wrap around like they do in the table
+func Evaluate(expression string, nBytes uint) ([]byte, error) {
+	tokens, err := tokenize(expression)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("empty expression")
+	}
+	p := parser{tokens: tokens, nBytes: nBytes}
+	result, err := p.parseExpression(1)
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected '%s'", p.tokens[p.pos])
+	}
+	return result, nil
+}
+
+// Consumes the next token if it is equal to the expected one
+func (p *parser) accept(expected string) bool {
+	if p.peek() == expected {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// Applies the operation and fits the result to the width of the expression
+func (p *parser) apply(op ops.Operation, operands ...[]byte) []byte {
+	result, _ := ops.FitToSize(op.Apply(operands...), p.nBytes)
+	return result
+}
+
+// Consumes the next token, which must be equal to the expected one
+func (p *parser) expect(expected string) error {
+	if !p.accept(expected) {
+		if p.peek() == "" {
+			return fmt.Errorf("expected '%s' at end of expression", expected)
+		}
+		return fmt.Errorf("expected '%s', got '%s'", expected, p.peek())
+	}
+	return nil
+}
+
+// Parses a sequence of operands separated by infix operators which bind at least as strongly as minPrecedence
+func (p *parser) parseExpression(minPrecedence int) ([]byte, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		symbol := p.peek()
+		precedence, isInfix := PRECEDENCE[symbol]
+		if !isInfix || precedence < minPrecedence {
+			return left, nil
+		}
+		p.pos++
+		op, ok := ops.LookupSymbol(symbol, 2)
+		if !ok {
+			return nil, fmt.Errorf("no operation for '%s'", symbol)
+		}
+		right, err := p.parseExpression(precedence + 1)
+		if err != nil {
+			return nil, err
+		}
+		left = p.apply(op, left, right)
+	}
+}
+
+// Parses a number, a parenthesized expression or a call to a named operation
+func (p *parser) parsePrimary() ([]byte, error) {
+	token := p.peek()
+	switch {
+	case token == "":
+		return nil, fmt.Errorf("unexpected end of expression")
+	case p.accept("("):
+		value, err := p.parseExpression(1)
+		if err != nil {
+			return nil, err
+		}
+		return value, p.expect(")")
+	case ('0' <= token[0] && token[0] <= '9') || ops.IsTextLiteral(token):
+		p.pos++
+		value, err := ops.StringToBytes(token)
+		if err != nil {
+			return nil, err
+		}
+		return ops.FitExactly(value, p.nBytes)
+	case isWordChar(token[0]):
+		p.pos++
+		if err := p.expect("("); err != nil {
+			return nil, fmt.Errorf("unknown name '%s'", token)
+		}
+		operands := [][]byte{}
+		for !p.accept(")") {
+			if len(operands) > 0 {
+				if err := p.expect(","); err != nil {
+					return nil, err
+				}
+			}
+			operand, err := p.parseExpression(1)
+			if err != nil {
+				return nil, err
+			}
+			operands = append(operands, operand)
+		}
+		op, ok := ops.LookupOperation(token, len(operands))
+		if !ok {
+			return nil, fmt.Errorf("no operation %s taking %d numbers", token, len(operands))
+		}
+		return p.apply(op, operands...), nil
+	default:
+		return nil, fmt.Errorf("unexpected '%s'", token)
+	}
+}
+
+// Parses an operand with any number of prefix operators
+func (p *parser) parseUnary() ([]byte, error) {
+	symbol := p.peek()
+	op, ok := ops.LookupSymbol(symbol, 1)
+	if !ok {
+		return p.parsePrimary()
+	}
+	p.pos++
+	operand, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	return p.apply(op, operand), nil
+}
+
+// Returns the next token, or "" at the end of the expression
+func (p *parser) peek() string {
+	if p.pos >= len(p.tokens) {
+		return ""
+	}
+	return p.tokens[p.pos]
+}
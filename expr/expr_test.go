@@ -0,0 +1,78 @@
+package expr
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/jonathangjertsen/jco-go/ops"
+	"testing"
+)
+
+func TestEvaluate(t *testing.T) {
+	var vector = []struct {
+		expression string
+		nBytes     uint
+		want       []byte
+	}{
+		{"0x1877", 2, []byte{0x18, 0x77}},
+		{"1 + 2", 1, []byte{3}},
+		{"1 + 2 << 3", 1, []byte{24}},
+		{"1 | 2 & 3", 1, []byte{3}},
+		{"(1 | 2) & 1", 1, []byte{1}},
+		{"~0", 2, []byte{0xff, 0xff}},
+		{"-1", 2, []byte{0xff, 0xff}},
+		{"0 - 1", 1, []byte{0xff}},
+		{"0xff + 1", 1, []byte{0x00}},
+		{"0x1877 >> 4", 2, []byte{0x01, 0x87}},
+		{"popcount(0x1877)", 2, []byte{0x00, 0x08}},
+		{"popcount(0x1877 & ~0xff) + 1", 2, []byte{0x00, 0x03}},
+		{"reverse_byteorder(0x1234)", 2, []byte{0x34, 0x12}},
+		{"xnor(0x0f, 0xff)", 1, []byte{0x0f}},
+		{"'a' - 'A'", 1, []byte{0x20}},
+		{`"AB" | 0x2020`, 2, []byte{0x61, 0x62}},
+		{"U+00e9 + 1", 2, []byte{0x00, 0xea}},
+	}
+	for _, tt := range vector {
+		testname := fmt.Sprintf("%v,%v\n", tt.expression, tt.nBytes)
+		t.Run(testname, func(t *testing.T) {
+			have, err := Evaluate(tt.expression, tt.nBytes)
+			if err != nil {
+				t.Errorf("Want %v, have error %v\n", tt.want, err)
+			} else if !bytes.Equal(have, tt.want) {
+				t.Errorf("Want %v, have %v\n", tt.want, have)
+			}
+		})
+	}
+}
+
+func TestEvaluateErrors(t *testing.T) {
+	for _, expression := range []string{
+		"",
+		"1 +",
+		"(1",
+		"1)",
+		"0xzz",
+		"0x123",
+		"foo(1)",
+		"popcount",
+		"popcount(1, 2)",
+		"1 $ 2",
+		"'a",
+		"'a' + U+",
+	} {
+		t.Run(expression, func(t *testing.T) {
+			if have, err := Evaluate(expression, 1); err == nil {
+				t.Errorf("Want error, have %v\n", have)
+			}
+		})
+	}
+}
+
+func TestEvaluateNumberErrors(t *testing.T) {
+	if _, err := Evaluate("1 + 0xzz", 1); !errors.Is(err, ops.ErrInvalidNumber) {
+		t.Errorf("Want ErrInvalidNumber, have %v\n", err)
+	}
+	if _, err := Evaluate("1 + 0x100", 1); !errors.Is(err, ops.ErrOverflow) {
+		t.Errorf("Want ErrOverflow, have %v\n", err)
+	}
+}
@@ -1,9 +1,15 @@
 module github.com/jonathangjertsen/jco-go
 
-go 1.16
+go 1.17
 
 require (
 	github.com/fatih/color v1.12.0
 	github.com/magefile/mage v1.11.0
+	golang.org/x/term v0.0.0-20210503060354-a79de5458b56
+)
+
+require (
+	github.com/mattn/go-colorable v0.1.8 // indirect
+	github.com/mattn/go-isatty v0.0.12 // indirect
 	golang.org/x/sys v0.0.0-20210510120138-977fb7262007 // indirect
 )
@@ -3,9 +3,10 @@ package main
 import (
 	"github.com/jonathangjertsen/jco-go/buildconfig"
 	"github.com/jonathangjertsen/jco-go/cmd"
+	"os"
 )
 
 func main() {
 	defer buildconfig.PanicHandler()
-	cmd.Execute()
+	os.Exit(cmd.Execute())
 }
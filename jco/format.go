@@ -0,0 +1,43 @@
+package jco
+
+import (
+	"github.com/jonathangjertsen/jco-go/ops"
+)
+
+// Returns the binary representation with every bit, e.g. 0b00010010
+func (v Value) Bin() string {
+	return v.BinGrouped(0, "")
+}
+
+// Returns the binary representation with sep between every group of bits, counting from the right
+func (v Value) BinGrouped(group uint, sep string) string {
+	return ops.BytesToBinGrouped(v.bytes, uint(len(v.bytes)), group, sep)
+}
+
+// Returns the decimal representation, with a minus sign if the value is negative
+func (v Value) Dec() string {
+	return v.DecGrouped(0, "")
+}
+
+// Returns the decimal representation with sep between every group of digits, counting from the right
+func (v Value) DecGrouped(group uint, sep string) string {
+	if v.IsNegative() {
+		return "-" + ops.BytesToDecGrouped(v.Neg().bytes, uint(len(v.bytes)), group, sep)
+	}
+	return ops.BytesToDecGrouped(v.bytes, uint(len(v.bytes)), group, sep)
+}
+
+// Returns the hexadecimal representation with every digit, e.g. 0x0012
+func (v Value) Hex() string {
+	return v.HexGrouped(0, "")
+}
+
+// Returns the hexadecimal representation with sep between every group of digits, counting from the right
+func (v Value) HexGrouped(group uint, sep string) string {
+	return ops.BytesToHexGrouped(v.bytes, uint(len(v.bytes)), group, sep)
+}
+
+// Returns the hexadecimal representation
+func (v Value) String() string {
+	return v.Hex()
+}
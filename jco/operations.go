@@ -0,0 +1,183 @@
+package jco
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/jonathangjertsen/jco-go/ops"
+)
+
+// Returns whether the result of the arithmetic operation differs from the exact result, with the operands and the
+// result read as signed. The exact result is found by sign-extending the operands to more than twice their width,
+// where sums, differences, negations and shifts by less than the width cannot overflow
+func signedOverflow(op ops.Operation, operands [][]byte, result []byte) bool {
+	extend := func(a []byte, length int) []byte {
+		fill := byte(0)
+		if len(a) > 0 && a[0]&0x80 != 0 {
+			fill = 0xff
+		}
+		extended := bytes.Repeat([]byte{fill}, length-len(a))
+		return append(extended, a...)
+	}
+	length := 2*len(result) + 1
+	extended := make([][]byte, 0, len(operands))
+	for _, operand := range operands {
+		extended = append(extended, extend(operand, length))
+	}
+	exact, _ := ops.FitToSize(op.Apply(extended...), uint(length))
+	return !bytes.Equal(exact, extend(result, length))
+}
+
+// Applies the registered operation, panicking if it is missing since the methods below only use built-in names
+func (v Value) mustApply(name string, others ...Value) Value {
+	result, _, err := v.Apply(name, others...)
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+// Returns the bytes of v followed by the bytes of the other operands resized to the width of v, which is what the
+// operations in ops expect. The operations leave their operands alone, so the bytes are not copied
+func (v Value) operands(others []Value) ([][]byte, error) {
+	operands := make([][]byte, 1, len(others)+1)
+	operands[0] = v.bytes
+	for _, other := range others {
+		if other.Bits() == v.Bits() {
+			operands = append(operands, other.bytes)
+			continue
+		}
+		resized, _, err := other.Resize(v.Bits())
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, resized.bytes)
+	}
+	return operands, nil
+}
+
+// Returns v + other, wrapping around at the width of v
+func (v Value) Add(other Value) Value {
+	return v.mustApply("add", other)
+}
+
+// Returns v & other
+func (v Value) And(other Value) Value {
+	return v.mustApply("and", other)
+}
+
+// Returns v &~ other, i.e. v with the bits that are set in other cleared
+func (v Value) AndNot(other Value) Value {
+	return v.mustApply("andnot", other)
+}
+
+// Applies the registered operation with the given name to v and the other operands, which are first resized to
+// the width of v. The result has the width and signedness of v, and the flag reports whether it had to be
+// truncated to fit: for unsigned values and bitwise operations a carry out of the top bit, and for arithmetic on
+// signed values a result outside the range of the signed type, like 0x7fff:i16 + 1
+func (v Value) Apply(name string, others ...Value) (Value, bool, error) {
+	op, ok := ops.LookupOperation(name, len(others)+1)
+	if !ok {
+		return Value{}, false, fmt.Errorf("%w: %s with %d operands", ErrUnknownOperation, name, len(others)+1)
+	}
+	operands, err := v.operands(others)
+	if err != nil {
+		return Value{}, false, err
+	}
+	result, truncated := ops.FitToSize(op.Apply(operands...), uint(len(v.bytes)))
+	if v.signed && op.Arithmetic {
+		truncated = signedOverflow(op, operands, result)
+	}
+	return Value{bytes: append([]byte{}, result...), signed: v.signed}, truncated, nil
+}
+
+// Returns why the result of the registered operation with the given name is meaningless for v and the other
+// operands, e.g. because they are not valid BCD, or nil if it is meaningful
+func (v Value) Check(name string, others ...Value) error {
+	op, ok := ops.LookupOperation(name, len(others)+1)
+	if !ok {
+		return fmt.Errorf("%w: %s with %d operands", ErrUnknownOperation, name, len(others)+1)
+	}
+	if op.Check == nil {
+		return nil
+	}
+	operands, err := v.operands(others)
+	if err != nil {
+		return err
+	}
+	return op.Check(operands...)
+}
+
+// Returns the number of leading zeros
+func (v Value) Clz() Value {
+	return v.mustApply("clz")
+}
+
+// Returns the number of bits needed to represent the value
+func (v Value) Nbits() Value {
+	return v.mustApply("nbits")
+}
+
+// Returns -v in two's complement, wrapping around at the width of v
+func (v Value) Neg() Value {
+	return v.mustApply("twos_complement")
+}
+
+// Returns ~v
+func (v Value) Not() Value {
+	return v.mustApply("not")
+}
+
+// Returns v | other
+func (v Value) Or(other Value) Value {
+	return v.mustApply("or", other)
+}
+
+// Returns the number of bits that are 1
+func (v Value) Popcount() Value {
+	return v.mustApply("popcount")
+}
+
+// Returns v with the bit order reversed within each byte
+func (v Value) ReverseBitorder() Value {
+	return v.mustApply("reverse_bitorder")
+}
+
+// Returns v with all bits in reverse order
+func (v Value) ReverseBitstring() Value {
+	return v.mustApply("reverse_bitstring")
+}
+
+// Returns v with the byte order reversed
+func (v Value) ReverseByteorder() Value {
+	return v.mustApply("reverse_byteorder")
+}
+
+// Returns v with the nibble order reversed within each byte
+func (v Value) ReverseNibbleorder() Value {
+	return v.mustApply("reverse_nibbleorder")
+}
+
+// Returns v << other. Bits shifted out of the width are lost
+func (v Value) Shl(other Value) Value {
+	return v.mustApply("shl", other)
+}
+
+// Returns v >> other. The shift is logical, so zeros are shifted in even if v is signed
+func (v Value) Shr(other Value) Value {
+	return v.mustApply("shr", other)
+}
+
+// Returns v - other, wrapping around at the width of v
+func (v Value) Sub(other Value) Value {
+	return v.mustApply("sub", other)
+}
+
+// Returns v ^~ other
+func (v Value) Xnor(other Value) Value {
+	return v.mustApply("xnor", other)
+}
+
+// Returns v ^ other
+func (v Value) Xor(other Value) Value {
+	return v.mustApply("xor", other)
+}
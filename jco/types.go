@@ -0,0 +1,91 @@
+package jco
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// An integer type: a width in bits and whether it is signed, written like u8 or i16
+type Type struct {
+	Bits   uint
+	Signed bool
+}
+
+// Parses a type like u8 or i16. The width must be a positive multiple of 8
+func ParseType(text string) (Type, error) {
+	if len(text) < 2 || (text[0] != 'u' && text[0] != 'i') {
+		return Type{}, fmt.Errorf("invalid type '%s', expected e.g. u8 or i16", text)
+	}
+	bits, err := strconv.ParseUint(text[1:], 10, 16)
+	if err != nil {
+		return Type{}, fmt.Errorf("invalid type '%s', expected e.g. u8 or i16", text)
+	}
+	if err := checkWidth(uint(bits)); err != nil {
+		return Type{}, err
+	}
+	return Type{Bits: uint(bits), Signed: text[0] == 'i'}, nil
+}
+
+// Returns the type that both operands are converted to by C's usual arithmetic conversions, for a C
+// implementation where int has intBits bits. Types narrower than int are first promoted to int. Then, if the
+// signedness differs, the unsigned type wins unless the signed type is wider
+func UsualArithmeticConversion(a, b Type, intBits uint) Type {
+	promote := func(t Type) Type {
+		if t.Bits < intBits {
+			return Type{Bits: intBits, Signed: true}
+		}
+		return t
+	}
+	a, b = promote(a), promote(b)
+	switch {
+	case a.Signed == b.Signed && a.Bits >= b.Bits:
+		return a
+	case a.Signed == b.Signed:
+		return b
+	case a.Signed && a.Bits > b.Bits:
+		return a
+	case b.Signed && b.Bits > a.Bits:
+		return b
+	case a.Signed:
+		return b
+	default:
+		return a
+	}
+}
+
+// Returns the wider of the types. If they are equally wide, the result is signed only if both are
+func Widest(a, b Type) Type {
+	switch {
+	case a.Bits > b.Bits:
+		return a
+	case b.Bits > a.Bits:
+		return b
+	default:
+		return Type{Bits: a.Bits, Signed: a.Signed && b.Signed}
+	}
+}
+
+// Returns the type as it is written, e.g. u8 or i16
+func (t Type) String() string {
+	return map[bool]string{false: "u", true: "i"}[t.Signed] + strconv.FormatUint(uint64(t.Bits), 10)
+}
+
+// Returns the value converted to the type like a cast in C: sign-extended if the value is signed and
+// zero-extended otherwise, or truncated, and then interpreted with the signedness of the type. Reports whether the
+// converted value is a different number, which happens when bits are cut off or the sign changes meaning
+func (v Value) Convert(t Type) (Value, bool, error) {
+	resized, truncated, err := v.Resize(t.Bits)
+	if err != nil {
+		return Value{}, false, err
+	}
+	converted := resized.AsUnsigned()
+	if t.Signed {
+		converted = resized.AsSigned()
+	}
+	return converted, truncated || converted.IsNegative() != v.IsNegative(), nil
+}
+
+// Returns the type of the value
+func (v Value) Type() Type {
+	return Type{Bits: v.Bits(), Signed: v.signed}
+}
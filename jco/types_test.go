@@ -0,0 +1,76 @@
+package jco
+
+import (
+	"testing"
+)
+
+func TestConvert(t *testing.T) {
+	var vector = []struct {
+		text    string
+		from    string
+		to      string
+		want    string
+		changed bool
+	}{
+		{"0x12", "u8", "i32", "18", false},
+		{"0xfff0", "i16", "i32", "-16", false},
+		{"0xfff0", "i16", "u32", "4294967280", true},
+		{"0xfff0", "u16", "i32", "65520", false},
+		{"0xc8", "u8", "i8", "-56", true},
+		{"0x1234", "u16", "u8", "52", true},
+		{"0x0034", "i16", "i8", "52", false},
+	}
+	for _, tt := range vector {
+		from, _ := ParseType(tt.from)
+		to, _ := ParseType(tt.to)
+		value := mustParse(t, tt.text, from.Bits)
+		if from.Signed {
+			value = value.AsSigned()
+		}
+		converted, changed, err := value.Convert(to)
+		if err != nil || converted.Dec() != tt.want || changed != tt.changed || converted.Type() != to {
+			t.Errorf("(%s)%s:%s: want %s changed=%v, have %s changed=%v type %s (%v)", tt.to, tt.text, tt.from, tt.want, tt.changed, converted.Dec(), changed, converted.Type(), err)
+		}
+	}
+}
+
+func TestParseType(t *testing.T) {
+	for _, text := range []string{"u8", "i16", "u64", "i128"} {
+		typ, err := ParseType(text)
+		if err != nil || typ.String() != text {
+			t.Errorf("Want %s, have %s (%v)", text, typ, err)
+		}
+	}
+	for _, text := range []string{"", "u", "x8", "u7", "i0", "u-8"} {
+		if _, err := ParseType(text); err == nil {
+			t.Errorf("Want an error for '%s'", text)
+		}
+	}
+}
+
+func TestPromotion(t *testing.T) {
+	var vector = []struct {
+		a      string
+		b      string
+		c      string
+		widest string
+	}{
+		{"u8", "i16", "i32", "i16"},
+		{"u8", "u8", "i32", "u8"},
+		{"u32", "i32", "u32", "u32"},
+		{"i32", "u32", "u32", "u32"},
+		{"u32", "i64", "i64", "i64"},
+		{"u64", "i32", "u64", "u64"},
+		{"i16", "i16", "i32", "i16"},
+	}
+	for _, tt := range vector {
+		a, _ := ParseType(tt.a)
+		b, _ := ParseType(tt.b)
+		if have := UsualArithmeticConversion(a, b, 32).String(); have != tt.c {
+			t.Errorf("%s, %s with C's rules: want %s, have %s", tt.a, tt.b, tt.c, have)
+		}
+		if have := Widest(a, b).String(); have != tt.widest {
+			t.Errorf("Widest of %s, %s: want %s, have %s", tt.a, tt.b, tt.widest, have)
+		}
+	}
+}
@@ -0,0 +1,238 @@
+// Package jco is the library behind the jco command: fixed-width integers with the operations and formatting
+// that the command shows
+package jco
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/jonathangjertsen/jco-go/ops"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+var (
+	// Returned (wrapped) when a width is not a positive multiple of 8 bits
+	ErrInvalidWidth = errors.New("invalid width")
+
+	// Returned (wrapped) when an operation is not registered with the given number of operands
+	ErrUnknownOperation = errors.New("unknown operation")
+)
+
+// A fixed-width integer. The zero Value is not valid, use New, Parse, FromUint64 or FromInt64.
+// Values are immutable: every method returns a new Value and leaves the receiver alone
+type Value struct {
+	// Big-endian bytes, exactly Bits()/8 of them
+	bytes []byte
+
+	// Whether the value is interpreted as two's complement when formatting and converting
+	signed bool
+}
+
+// Returns an error unless the width is a positive multiple of 8 bits
+func checkWidth(bits uint) error {
+	if bits == 0 || bits%8 != 0 {
+		return fmt.Errorf("%w: %d bits, expected a positive multiple of 8", ErrInvalidWidth, bits)
+	}
+	return nil
+}
+
+// Returns a signed value with the given width holding the number
+func FromInt64(number int64, bits uint) (Value, error) {
+	value, err := Parse(strconv.FormatInt(number, 10), bits)
+	if err != nil {
+		return Value{}, err
+	}
+	if number >= 0 && value.AsSigned().IsNegative() {
+		return Value{}, fmt.Errorf("%w: %d does not fit in %d signed bits", ops.ErrOverflow, number, bits)
+	}
+	return value.AsSigned(), nil
+}
+
+// Returns an unsigned value with the given width holding the number
+func FromUint64(number uint64, bits uint) (Value, error) {
+	var buffer [8]byte
+	binary.BigEndian.PutUint64(buffer[:], number)
+	return New(buffer[:], bits)
+}
+
+// Returns the width that the number is written with: 4 bits per hex digit, 3 per octal digit and 1 per binary digit,
+// counting leading zeros, so that 0x00ff is 16 bits. Decimal numbers get the smallest width that holds them. The
+// width is rounded up to a multiple of 8, and always has room for the value and the sign bit of negative numbers.
+// String literals get 8 bits per byte, and characters and codepoints the smallest width that holds them
+func InferWidth(text string) (uint, error) {
+	if ops.IsTextLiteral(text) {
+		bytes, err := ops.TextToBytes(text)
+		if err != nil {
+			return 0, err
+		}
+		return 8 * uint(ops.Intmax(len(bytes), 1)), nil
+	}
+	number, ok := big.NewInt(0).SetString(text, 0)
+	if !ok {
+		return 0, fmt.Errorf("%w '%s'", ops.ErrInvalidNumber, text)
+	}
+	digits := strings.ToLower(strings.ReplaceAll(strings.TrimLeft(text, "+-"), "_", ""))
+	bits := uint(0)
+	switch {
+	case strings.HasPrefix(digits, "0x"):
+		bits = 4 * uint(len(digits)-len("0x"))
+	case strings.HasPrefix(digits, "0b"):
+		bits = uint(len(digits) - len("0b"))
+	case strings.HasPrefix(digits, "0o"):
+		bits = 3 * uint(len(digits)-len("0o"))
+	case len(digits) > 1 && digits[0] == '0':
+		bits = 3 * uint(len(digits)-1)
+	}
+
+	// A negative number -m needs the bits of m-1 plus the sign bit
+	needed := uint(number.BitLen())
+	if number.Sign() < 0 {
+		magnitude := big.NewInt(0).Neg(number)
+		needed = uint(magnitude.Sub(magnitude, big.NewInt(1)).BitLen()) + 1
+	}
+	if needed > bits {
+		bits = needed
+	}
+	if bits == 0 {
+		bits = 8
+	}
+	return 8 * ((bits + 7) / 8), nil
+}
+
+// Returns an unsigned value with the given width holding the big-endian bytes, or an error if they do not fit
+func New(bytes []byte, bits uint) (Value, error) {
+	if err := checkWidth(bits); err != nil {
+		return Value{}, err
+	}
+	fitted, err := ops.FitExactly(bytes, bits/8)
+	if err != nil {
+		return Value{}, err
+	}
+	return Value{bytes: append([]byte{}, fitted...)}, nil
+}
+
+// Parses a number like 0x1877, 0b1010, 0o17 or 1234 into a value with the given width. Numbers with a minus sign
+// are stored in two's complement and give a signed value, other numbers give an unsigned value. Text like 'A',
+// "abc" or U+1F600 gives an unsigned value holding its codepoint or bytes, see ops.TextToBytes
+func Parse(text string, bits uint) (Value, error) {
+	if ops.IsTextLiteral(text) {
+		bytes, err := ops.TextToBytes(text)
+		if err != nil {
+			return Value{}, err
+		}
+		return New(bytes, bits)
+	}
+	number, ok := big.NewInt(0).SetString(text, 0)
+	if !ok {
+		return Value{}, fmt.Errorf("%w '%s'", ops.ErrInvalidNumber, text)
+	}
+	if !strings.HasPrefix(text, "-") {
+		return New(number.Bytes(), bits)
+	}
+	magnitude, err := New(number.Neg(number).Bytes(), bits)
+	if err != nil {
+		return Value{}, err
+	}
+	negated := magnitude.Neg().AsSigned()
+	if !magnitude.IsZero() && !negated.IsNegative() {
+		return Value{}, fmt.Errorf("%w: %s does not fit in %d bits", ops.ErrOverflow, text, bits)
+	}
+	return negated, nil
+}
+
+// Returns the value interpreted as signed (two's complement)
+func (v Value) AsSigned() Value {
+	return Value{bytes: v.bytes, signed: true}
+}
+
+// Returns the value interpreted as unsigned
+func (v Value) AsUnsigned() Value {
+	return Value{bytes: v.bytes, signed: false}
+}
+
+// Returns the width in bits
+func (v Value) Bits() uint {
+	return 8 * uint(len(v.bytes))
+}
+
+// Returns a copy of the big-endian bytes, exactly Bits()/8 of them
+func (v Value) Bytes() []byte {
+	return append([]byte{}, v.bytes...)
+}
+
+// Returns whether the values have the same width, signedness and bits
+func (v Value) Equal(other Value) bool {
+	return v.signed == other.signed && string(v.bytes) == string(other.bytes)
+}
+
+// Returns the value as an int64, sign-extended if the value is signed. Only the low 64 bits are used
+func (v Value) Int64() int64 {
+	if v.Bits() < 64 && v.IsNegative() {
+		return int64(v.Uint64()) - int64(1)<<v.Bits()
+	}
+	return int64(v.Uint64())
+}
+
+// Returns whether the value is signed and the sign bit is set
+func (v Value) IsNegative() bool {
+	return v.signed && len(v.bytes) > 0 && v.bytes[0]&0x80 != 0
+}
+
+// Returns whether all bits are zero
+func (v Value) IsZero() bool {
+	for _, b := range v.bytes {
+		if b != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Returns the value with the given width, sign-extended if the value is signed and zero-extended otherwise,
+// and reports whether any significant bits were cut off when narrowing
+func (v Value) Resize(bits uint) (Value, bool, error) {
+	if err := checkWidth(bits); err != nil {
+		return Value{}, false, err
+	}
+	fill := byte(0)
+	if v.IsNegative() {
+		fill = 0xff
+	}
+	nBytes := int(bits / 8)
+	resized := make([]byte, nBytes)
+	for i := range resized {
+		resized[i] = fill
+	}
+
+	// Copy from the least significant byte, and check that the bytes that are cut off only held the extension
+	truncated := false
+	for i := range v.bytes {
+		source := len(v.bytes) - 1 - i
+		if i < nBytes {
+			resized[nBytes-1-i] = v.bytes[source]
+		} else if v.bytes[source] != fill {
+			truncated = true
+		}
+	}
+	narrowed := Value{bytes: resized, signed: v.signed}
+	if v.signed && bits < v.Bits() && narrowed.IsNegative() != v.IsNegative() {
+		truncated = true
+	}
+	return narrowed, truncated, nil
+}
+
+// Returns whether the value is signed
+func (v Value) Signed() bool {
+	return v.signed
+}
+
+// Returns the low 64 bits of the value, without sign extension
+func (v Value) Uint64() uint64 {
+	number := uint64(0)
+	for _, b := range v.bytes {
+		number = number<<8 | uint64(b)
+	}
+	return number
+}
@@ -0,0 +1,292 @@
+package jco
+
+import (
+	"errors"
+	"fmt"
+	"github.com/jonathangjertsen/jco-go/ops"
+	"strings"
+	"testing"
+	"testing/quick"
+)
+
+// Parses the number, failing the test if it can not be parsed
+func mustParse(t *testing.T, text string, bits uint) Value {
+	t.Helper()
+	value, err := Parse(text, bits)
+	if err != nil {
+		t.Fatalf("Parse(%s, %d): %v", text, bits, err)
+	}
+	return value
+}
+
+func TestApply(t *testing.T) {
+	a := mustParse(t, "0xff", 8)
+	sum, truncated, err := a.Apply("add", mustParse(t, "1", 8))
+	if err != nil || !truncated || !sum.IsZero() {
+		t.Errorf("Want truncated zero, have %s truncated=%v (%v)", sum, truncated, err)
+	}
+	if _, _, err := a.Apply("add"); !errors.Is(err, ErrUnknownOperation) {
+		t.Errorf("Want ErrUnknownOperation for add with one operand, have %v", err)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	var vector = []struct {
+		name    string
+		a       Value
+		b       Value
+		invalid bool
+	}{
+		{"valid", mustParse(t, "0x12", 8), mustParse(t, "0x34", 8), false},
+		{"invalid", mustParse(t, "0x12", 8), mustParse(t, "0x3a", 8), true},
+		{"wider operand narrowed to valid digits", mustParse(t, "0x12", 8), mustParse(t, "0xa034", 16), false},
+		{"narrower operand widened", mustParse(t, "0x0012", 16), mustParse(t, "0xa5", 8), true},
+	}
+	for _, tt := range vector {
+		t.Run(tt.name, func(t *testing.T) {
+			// Check must look at the same operands as Apply does
+			sum, _, err := tt.a.Apply("bcd_add", tt.b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = tt.a.Check("bcd_add", tt.b)
+			if (err != nil) != tt.invalid {
+				t.Errorf("Want invalid=%v for bcd_add(%s, %s) = %s, have %v", tt.invalid, tt.a.Hex(), tt.b.Hex(), sum.Hex(), err)
+			}
+		})
+	}
+	if err := mustParse(t, "1", 8).Check("bcd_add"); !errors.Is(err, ErrUnknownOperation) {
+		t.Errorf("Want ErrUnknownOperation for bcd_add with one operand, have %v", err)
+	}
+}
+
+func TestFormat(t *testing.T) {
+	value := mustParse(t, "-1234567", 32)
+	if have := value.DecGrouped(3, ","); have != "-1,234,567" {
+		t.Errorf("Want -1,234,567, have %s", have)
+	}
+	if have := value.AsUnsigned().Dec(); have != "4293732729" {
+		t.Errorf("Want 4293732729, have %s", have)
+	}
+	if have := value.HexGrouped(4, "_"); have != "0xffed_2979" {
+		t.Errorf("Want 0xffed_2979, have %s", have)
+	}
+	if have := mustParse(t, "5", 8).Bin(); have != "0b00000101" {
+		t.Errorf("Want 0b00000101, have %s", have)
+	}
+}
+
+func TestFromInt64(t *testing.T) {
+	for _, number := range []int64{0, 1, -1, 127, -128} {
+		value, err := FromInt64(number, 8)
+		if err != nil || value.Int64() != number {
+			t.Errorf("Want %d, have %d (%v)", number, value.Int64(), err)
+		}
+	}
+	if _, err := FromInt64(128, 8); !errors.Is(err, ops.ErrOverflow) {
+		t.Errorf("Want overflow for 128 in 8 signed bits, have %v", err)
+	}
+
+	// Property: FromInt64 and Int64 round trip at 64 bits
+	if err := quick.Check(func(number int64) bool {
+		value, err := FromInt64(number, 64)
+		return err == nil && value.Int64() == number
+	}, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestInferWidth(t *testing.T) {
+	var vector = []struct {
+		text string
+		want uint
+	}{
+		{"0x00ff", 16},
+		{"0xff", 8},
+		{"0x1", 8},
+		{"0x12345", 24},
+		{"0b0000_0001", 8},
+		{"0b1_0000_0000", 16},
+		{"0o777", 16},
+		{"0", 8},
+		{"255", 8},
+		{"256", 16},
+		{"-128", 8},
+		{"-129", 16},
+		{"-0xff", 16},
+		{"'A'", 8},
+		{`"abc"`, 24},
+		{`"\0"`, 8},
+		{"U+1F600", 24},
+	}
+	for _, tt := range vector {
+		have, err := InferWidth(tt.text)
+		if err != nil || have != tt.want {
+			t.Errorf("InferWidth(%s): want %d, have %d (%v)", tt.text, tt.want, have, err)
+		}
+	}
+	if _, err := InferWidth("0xg"); !errors.Is(err, ops.ErrInvalidNumber) {
+		t.Errorf("Want ErrInvalidNumber, have %v", err)
+	}
+}
+
+func TestOperations(t *testing.T) {
+	a := mustParse(t, "0x1877", 16)
+	b := mustParse(t, "0x00f0", 16)
+	var vector = []struct {
+		name string
+		have Value
+		want string
+	}{
+		{"not", a.Not(), "0xe788"},
+		{"neg", a.Neg(), "0xe789"},
+		{"popcount", a.Popcount(), "0x0008"},
+		{"clz", a.Clz(), "0x0003"},
+		{"nbits", a.Nbits(), "0x000d"},
+		{"reverse_bitstring", a.ReverseBitstring(), "0xee18"},
+		{"reverse_bitorder", a.ReverseBitorder(), "0x18ee"},
+		{"reverse_byteorder", a.ReverseByteorder(), "0x7718"},
+		{"reverse_nibbleorder", a.ReverseNibbleorder(), "0x8177"},
+		{"add", a.Add(b), "0x1967"},
+		{"add wraps", a.Not().Add(a).Add(mustParse(t, "1", 16)), "0x0000"},
+		{"sub", b.Sub(a), "0xe879"},
+		{"or", a.Or(b), "0x18f7"},
+		{"and", a.And(b), "0x0070"},
+		{"xor", a.Xor(b), "0x1887"},
+		{"xnor", a.Xnor(b), "0xe778"},
+		{"andnot", a.AndNot(b), "0x1807"},
+		{"shl", a.Shl(mustParse(t, "4", 16)), "0x8770"},
+		{"shr", a.Shr(mustParse(t, "4", 16)), "0x0187"},
+		{"shr is logical", mustParse(t, "-16", 16).Shr(mustParse(t, "4", 16)), "0x0fff"},
+		{"narrower operand", a.Add(mustParse(t, "1", 8)), "0x1878"},
+		{"signed operand is sign-extended", a.Add(mustParse(t, "-1", 8)), "0x1876"},
+	}
+	for _, tt := range vector {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.have.Hex() != tt.want {
+				t.Errorf("Want %s, have %s", tt.want, tt.have.Hex())
+			}
+		})
+	}
+
+	// Operations must leave the operands alone
+	if a.Hex() != "0x1877" || b.Hex() != "0x00f0" {
+		t.Errorf("Operands were modified: %s, %s", a, b)
+	}
+}
+
+func TestOverflow(t *testing.T) {
+	var vector = []struct {
+		a, op, b string
+		want     bool
+	}{
+		{"0xffff:u16", "add", "1:u16", true},
+		{"-16:i16", "add", "18:i16", false},
+		{"0x7fff:i16", "add", "1:i16", true},
+		{"-32768:i16", "sub", "1:i16", true},
+		{"-32768:i16", "twos_complement", "", true},
+		{"-1:i16", "twos_complement", "", false},
+		{"0x4000:i16", "shl", "1:i16", true},
+		{"-1:i16", "shl", "15:i16", false},
+		{"-1:i16", "xor", "1:i16", false},
+	}
+	for _, tt := range vector {
+		t.Run(tt.a+" "+tt.op+" "+tt.b, func(t *testing.T) {
+			parse := func(text string) Value {
+				colon := strings.Index(text, ":")
+				typ, _ := ParseType(text[colon+1:])
+				value, err := Parse(text[:colon], typ.Bits)
+				if err != nil {
+					t.Fatal(err)
+				}
+				value, _, _ = value.Convert(typ)
+				return value
+			}
+			others := []Value{}
+			if tt.b != "" {
+				others = append(others, parse(tt.b))
+			}
+			_, overflow, err := parse(tt.a).Apply(tt.op, others...)
+			if err != nil || overflow != tt.want {
+				t.Errorf("Want overflow %v, have %v (%v)", tt.want, overflow, err)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	var vector = []struct {
+		text   string
+		bits   uint
+		hex    string
+		dec    string
+		signed bool
+	}{
+		{"0x1877", 16, "0x1877", "6263", false},
+		{"0b101", 8, "0x05", "5", false},
+		{"255", 8, "0xff", "255", false},
+		{"-1", 8, "0xff", "-1", true},
+		{"-128", 8, "0x80", "-128", true},
+		{"-0x1877", 32, "0xffffe789", "-6263", true},
+		{"-0", 8, "0x00", "0", true},
+		{"'A'", 16, "0x0041", "65", false},
+		{`"a\n"`, 16, "0x610a", "24842", false},
+		{"U+00e9", 16, "0x00e9", "233", false},
+	}
+	for _, tt := range vector {
+		t.Run(tt.text, func(t *testing.T) {
+			value := mustParse(t, tt.text, tt.bits)
+			if value.Hex() != tt.hex || value.Dec() != tt.dec || value.Signed() != tt.signed || value.Bits() != tt.bits {
+				t.Errorf("Want %s %s signed=%v, have %s %s signed=%v", tt.hex, tt.dec, tt.signed, value.Hex(), value.Dec(), value.Signed())
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	var vector = []struct {
+		text string
+		bits uint
+		want error
+	}{
+		{"0x", 8, ops.ErrInvalidNumber},
+		{"popcount", 8, ops.ErrInvalidNumber},
+		{"256", 8, ops.ErrOverflow},
+		{"-129", 8, ops.ErrOverflow},
+		{"1", 0, ErrInvalidWidth},
+		{"1", 12, ErrInvalidWidth},
+	}
+	for _, tt := range vector {
+		t.Run(fmt.Sprintf("%s/%d", tt.text, tt.bits), func(t *testing.T) {
+			if _, err := Parse(tt.text, tt.bits); !errors.Is(err, tt.want) {
+				t.Errorf("Want %v, have %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestResize(t *testing.T) {
+	var vector = []struct {
+		text      string
+		from      uint
+		to        uint
+		want      string
+		truncated bool
+	}{
+		{"0x12", 8, 16, "0x0012", false},
+		{"-2", 8, 16, "0xfffe", false},
+		{"0x1234", 16, 8, "0x34", true},
+		{"0x0034", 16, 8, "0x34", false},
+		{"-2", 16, 8, "0xfe", false},
+		{"-200", 16, 8, "0x38", true},
+		{"128", 16, 8, "0x80", false},
+	}
+	for _, tt := range vector {
+		t.Run(fmt.Sprintf("%s/%d/%d", tt.text, tt.from, tt.to), func(t *testing.T) {
+			resized, truncated, err := mustParse(t, tt.text, tt.from).Resize(tt.to)
+			if err != nil || resized.Hex() != tt.want || truncated != tt.truncated {
+				t.Errorf("Want %s truncated=%v, have %s truncated=%v (%v)", tt.want, tt.truncated, resized, truncated, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package ops
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// Returns an error naming the first invalid digit, counting from the most significant byte, and how many others
+// there are. The digits are the nibbles of the bytes for packed BCD and the bytes themselves for unpacked BCD
+func invalidDigits(a []byte, packed bool) error {
+	first := ""
+	count := 0
+	for i, b := range a {
+		highBit := 8*(len(a)-i) - 1
+		switch {
+		case !packed && (b&0xf > 9 || (b>>4 != 0 && b>>4 != 3)):
+			if count == 0 {
+				first = fmt.Sprintf("0x%02x in bits [%d:%d] is not an unpacked BCD digit", b, highBit, highBit-7)
+			}
+			count++
+		case packed:
+			for n, nibble := range []byte{b >> 4, b & 0xf} {
+				if nibble > 9 {
+					if count == 0 {
+						first = fmt.Sprintf("0x%x in bits [%d:%d] is not a decimal digit", nibble, highBit-4*n, highBit-4*n-3)
+					}
+					count++
+				}
+			}
+		}
+	}
+	switch count {
+	case 0:
+		return nil
+	case 1:
+		return fmt.Errorf("%s", first)
+	default:
+		return fmt.Errorf("%s (and %d more)", first, count-1)
+	}
+}
+
+// Applies f to each pair of nibbles in a and b, starting from the least significant, and returns the resulting
+// digits with the length of the longer input and the final carry. f receives the carry from the previous pair and
+// returns the digit and the carry to the next pair
+func nibbleOp(a, b []byte, f func(an, bn, carry byte) (byte, byte)) ([]byte, byte) {
+	a, b = PadToEqualSize(a, b)
+	answer := make([]byte, len(a))
+	carry := byte(0)
+	for i := len(a) - 1; i >= 0; i-- {
+		var low, high byte
+		low, carry = f(a[i]&0xf, b[i]&0xf, carry)
+		high, carry = f(a[i]>>4, b[i]>>4, carry)
+		answer[i] = high<<4 | low
+	}
+	return answer, carry
+}
+
+// Adds a and b, both packed BCD, like an add followed by the decimal adjust of DAA: a nibble that ends up above 9
+// gets 6 added to it and carries into the next one. The carry out of the longer input gives an extra 0x01 byte
+func BCDAdd(a, b []byte) []byte {
+	answer, carry := nibbleOp(a, b, func(an, bn, carry byte) (byte, byte) {
+		sum := an + bn + carry
+		if sum > 9 {
+			return (sum + 6) & 0xf, 1
+		}
+		return sum, 0
+	})
+	if carry != 0 {
+		return append([]byte{carry}, answer...)
+	}
+	return answer
+}
+
+// Subtracts b from a, both packed BCD, like a subtract followed by the decimal adjust of DAS: a nibble that borrows
+// gets 6 subtracted from it. The result wraps around to the ten's complement at the length of the longer input, so
+// that 0x0000 - 0x0001 is 0x9999
+func BCDSubtract(a, b []byte) []byte {
+	answer, _ := nibbleOp(a, b, func(an, bn, borrow byte) (byte, byte) {
+		if an < bn+borrow {
+			return (an - bn - borrow - 6) & 0xf, 1
+		}
+		return an - bn - borrow, 0
+	})
+	return answer
+}
+
+// Returns an error describing the nibbles which are not decimal digits if the bytes are not valid packed BCD
+func CheckPackedBCD(a []byte) error {
+	return invalidDigits(a, true)
+}
+
+// Returns an error describing the bytes which are not digits if the bytes are not valid unpacked BCD, where each
+// byte is a digit from 0x00 to 0x09 or an ASCII digit from 0x30 to 0x39
+func CheckUnpackedBCD(a []byte) error {
+	return invalidDigits(a, false)
+}
+
+// Returns the number written by the nibbles of a as decimal digits, so that 0x1234 gives 1234. A nibble which is
+// not a decimal digit still counts with its value, e.g. 0x1a gives 1*10 + 10
+func FromPackedBCD(a []byte) []byte {
+	number := big.NewInt(0)
+	ten := big.NewInt(10)
+	for _, b := range a {
+		number.Mul(number, ten).Add(number, big.NewInt(int64(b>>4)))
+		number.Mul(number, ten).Add(number, big.NewInt(int64(b&0xf)))
+	}
+	return number.Bytes()
+}
+
+// Returns the number written by the bytes of a as decimal digits, one digit in the low nibble of each byte, so that
+// 0x010203 gives 123. The high nibble is ignored like the ASCII adjusts of x86 do, so ASCII digits work as well
+func FromUnpackedBCD(a []byte) []byte {
+	number := big.NewInt(0)
+	ten := big.NewInt(10)
+	for _, b := range a {
+		number.Mul(number, ten).Add(number, big.NewInt(int64(b&0xf)))
+	}
+	return number.Bytes()
+}
+
+// Returns the decimal digits of the unsigned number as packed BCD, two digits to a byte, so that 1234 gives 0x1234
+func ToBCD(a []byte) []byte {
+	digits := big.NewInt(0).SetBytes(a).String()
+	if len(digits)%2 == 1 {
+		digits = "0" + digits
+	}
+	answer := make([]byte, len(digits)/2)
+	for i := range answer {
+		answer[i] = (digits[2*i]-'0')<<4 | (digits[2*i+1] - '0')
+	}
+	return answer
+}
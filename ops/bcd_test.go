@@ -0,0 +1,94 @@
+package ops
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"testing"
+)
+
+func TestBCD(t *testing.T) {
+	var vector = []struct {
+		name  string
+		f     func(a []byte) []byte
+		input []byte
+		want  []byte
+	}{
+		{"ToBCD", ToBCD, []byte{0x04, 0xd2}, []byte{0x12, 0x34}},
+		{"ToBCD", ToBCD, []byte{0xff, 0xff}, []byte{0x06, 0x55, 0x35}},
+		{"ToBCD", ToBCD, []byte{}, []byte{0x00}},
+		{"FromPackedBCD", FromPackedBCD, []byte{0x12, 0x34}, []byte{0x04, 0xd2}},
+		{"FromPackedBCD", FromPackedBCD, []byte{0x1a}, []byte{20}},
+		{"FromUnpackedBCD", FromUnpackedBCD, []byte{0x01, 0x02, 0x03}, []byte{123}},
+		{"FromUnpackedBCD", FromUnpackedBCD, []byte("42"), []byte{42}},
+	}
+	for _, tt := range vector {
+		testname := fmt.Sprintf("%s(%#v)", tt.name, tt.input)
+		t.Run(testname, func(t *testing.T) {
+			if have := tt.f(tt.input); !bytes.Equal(have, tt.want) {
+				t.Errorf("Want %#v, have %#v", tt.want, have)
+			}
+		})
+	}
+
+	// Property: decoding the BCD of a number gives back the number
+	check(t, func(a []byte) bool {
+		decoded := FromPackedBCD(ToBCD(a))
+		return CheckPackedBCD(ToBCD(a)) == nil && big.NewInt(0).SetBytes(decoded).Cmp(big.NewInt(0).SetBytes(a)) == 0
+	})
+}
+
+func TestBCDArithmetic(t *testing.T) {
+	var vector = []struct {
+		name string
+		f    func(a, b []byte) []byte
+		a    []byte
+		b    []byte
+		want []byte
+	}{
+		{"BCDAdd", BCDAdd, []byte{0x19}, []byte{0x01}, []byte{0x20}},
+		{"BCDAdd", BCDAdd, []byte{0x99}, []byte{0x01}, []byte{0x01, 0x00}},
+		{"BCDAdd", BCDAdd, []byte{0x01, 0x99}, []byte{0x99}, []byte{0x02, 0x98}},
+		{"BCDSubtract", BCDSubtract, []byte{0x20}, []byte{0x01}, []byte{0x19}},
+		{"BCDSubtract", BCDSubtract, []byte{0x00, 0x00}, []byte{0x01}, []byte{0x99, 0x99}},
+		{"BCDSubtract", BCDSubtract, []byte{0x10, 0x00}, []byte{0x09, 0x99}, []byte{0x00, 0x01}},
+	}
+	for _, tt := range vector {
+		testname := fmt.Sprintf("%s(%#v,%#v)", tt.name, tt.a, tt.b)
+		t.Run(testname, func(t *testing.T) {
+			if have := tt.f(tt.a, tt.b); !bytes.Equal(have, tt.want) {
+				t.Errorf("Want %#v, have %#v", tt.want, have)
+			}
+		})
+	}
+}
+
+func TestCheckBCD(t *testing.T) {
+	var vector = []struct {
+		input    []byte
+		packed   string
+		unpacked string
+	}{
+		{[]byte{0x09, 0x39}, "", ""},
+		{[]byte{0x12, 0x34}, "", "0x12 in bits [15:8] is not an unpacked BCD digit"},
+		{[]byte{0x1a, 0xb0}, "0xa in bits [11:8] is not a decimal digit (and 1 more)", "0x1a in bits [15:8] is not an unpacked BCD digit (and 1 more)"},
+	}
+	for _, tt := range vector {
+		for _, c := range []struct {
+			name  string
+			check func(a []byte) error
+			want  string
+		}{{"CheckPackedBCD", CheckPackedBCD, tt.packed}, {"CheckUnpackedBCD", CheckUnpackedBCD, tt.unpacked}} {
+			testname := fmt.Sprintf("%s(%#v)", c.name, tt.input)
+			t.Run(testname, func(t *testing.T) {
+				have := ""
+				if err := c.check(tt.input); err != nil {
+					have = err.Error()
+				}
+				if have != c.want {
+					t.Errorf("Want %q, have %q", c.want, have)
+				}
+			})
+		}
+	}
+}
@@ -1,28 +1,35 @@
 package ops
 
 import (
+	"bytes"
 	"encoding/binary"
-	"encoding/hex"
+	"errors"
 	"fmt"
 	"math/big"
 	"math/bits"
+	"strconv"
+	"strings"
 )
 
 const (
 	MAX_SLICE_SIZE = 1000
 )
 
+var (
+	// Returned (wrapped) when a string can not be parsed as a number
+	ErrInvalidNumber = errors.New("invalid number")
+
+	// Returned (wrapped) when a number does not fit in the requested size
+	ErrOverflow = errors.New("overflow")
+)
+
 // Converts a big-endian byte array to uint64
 func bytesToUint64(input []byte) (uint64, error) {
 	input = trimLeadingZeros(input)
-	if !fitsInUint64(input) {
+	if len(input) > WORD_BYTES {
 		return 0, fmt.Errorf("%v is not representable as uint64", input)
 	}
-	num := uint64(0)
-	for i, b := range ByteReverse(input) {
-		num += uint64(b) << (8 * i)
-	}
-	return num, nil
+	return loadUint64(input), nil
 }
 
 // Adds two bytes, returning the sum (i.e.: (a+b)%256) and carry (i.e.: (a+b)/256)
@@ -50,11 +57,6 @@ func firstNonZeroIndex(input []byte) uint {
 	return Ulen(input)
 }
 
-// Returns whether the result can be represented as a uint64
-func fitsInUint64(a []byte) bool {
-	return nbitsAsUint64(a) <= 64
-}
-
 func nbitsAsUint64(input []byte) uint64 {
 	trimmed := trimLeadingZeros(input)
 	sumUint64 := uint64(0)
@@ -102,39 +104,23 @@ func uint64ToBytes(input uint64) []byte {
 
 // Adds a and b, both representing big-endian numbers
 func Add(a, b []byte) []byte {
-	a, b = PadToEqualSize(a, b)
-
-	// We will iterate from LSB to MSB for the carry to work
-	a = ByteReverse(a)
-	b = ByteReverse(b)
-	answer := make([]byte, len(a))
-	for i := 0; i < len(a); i++ {
-		// Get the sum and carry of the a and b parts
-		sum, carry := byteAdd(a[i], b[i])
-
-		// Add the sum to the existing value in this slot, which
-		// may also overflow in which case we need an additional carry
-		previousCarryPlusSum, extraCarry := byteAdd(answer[i], sum)
-		carry += extraCarry
-		answer[i] = previousCarryPlusSum
-
-		// Add the carry to the next slot, or create a slot for it if this is the last one
-		if i+1 >= len(answer) {
-			if carry > 0 {
-				answer = append(answer, carry)
-			}
-		} else {
-			answer[i+1] += carry
-		}
+	// Leave room for the carry out of the longer input, and drop that byte again if there is no carry
+	answer := make([]byte, Intmax(len(a), len(b))+1)
+	carry := uint64(0)
+	for k := 0; k < nWords(len(answer)); k++ {
+		var sum uint64
+		sum, carry = bits.Add64(loadWord(a, k), loadWord(b, k), carry)
+		storeWord(answer, k, sum)
 	}
-
-	// Flip back to big endian
-	return ByteReverse(answer)
+	if answer[0] == 0 {
+		return answer[1:]
+	}
+	return answer
 }
 
 // Returns bitwise a AND b
 func And(a, b []byte) []byte {
-	return BinaryOp(a, b, func(ai, bi byte) byte { return ai & bi })
+	return WordOp(a, b, func(ai, bi uint64) uint64 { return ai & bi })
 }
 
 // Returns bitwise OP(a, b)
@@ -159,39 +145,101 @@ func BitReverse(input []byte) []byte {
 	return UnaryOp(input, bits.Reverse8)
 }
 
+// Returns a line which labels every nibble of the BytesToBinGrouped output with the index of its most significant bit
+func BitRuler(nBytes uint, group uint, sep string) string {
+	binary := BytesToBinGrouped(Zeros(nBytes), nBytes, group, sep)
+	ruler := []byte(strings.Repeat(" ", len(binary)))
+
+	// Walk the digits MSb first, remembering where the previous label ended so that labels never overlap. The position
+	// of each digit follows from its index and the grouping, since the separator may itself contain digits
+	nBits := 8 * nBytes
+	grouped := group > 0 && nBits > group
+	pos := len("0b")
+	nextFree := 0
+	for i := uint(0); i < nBits; i++ {
+		if grouped && i > 0 && (nBits-i)%group == 0 {
+			pos += len(sep)
+		}
+		bit := nBits - 1 - i
+		if (bit+1)%4 == 0 && pos >= nextFree {
+			label := fmt.Sprintf("%d", bit)
+			if pos+len(label) <= len(ruler) {
+				copy(ruler[pos:], label)
+				nextFree = pos + len(label) + 1
+			}
+		}
+		pos++
+	}
+	return string(ruler)
+}
+
 // Returns the binary string representation of the bytes
 func BytesToBin(a []byte, nBytes uint) string {
-	parts := []byte("0b")
+	var builder strings.Builder
+	builder.Grow(len("0b") + 8*len(a))
+	builder.WriteString("0b")
 
-	// Iterate over each byte MSB first
+	// Iterate over each byte MSB first, and each bit in the byte MSb first
 	for _, b := range a {
-		// Iterate over each bit in the byte MSb first
 		for i := 7; i >= 0; i-- {
-			// If the bit is set, emit "1", else emit "0"
-			mask := byte(1 << i)
-			masked := mask & b
-			if masked == 0 {
-				parts = append(parts, []byte("0")...)
-			} else {
-				parts = append(parts, []byte("1")...)
-			}
+			builder.WriteByte('0' + (b>>i)&1)
 		}
 	}
-	return string(parts)
+	return builder.String()
+}
+
+// Returns the binary string representation of the bytes, with sep between every group of bits
+func BytesToBinGrouped(a []byte, nBytes uint, group uint, sep string) string {
+	if group == 0 {
+		return BytesToBin(a, nBytes)
+	}
+	return "0b" + GroupDigits(BytesToBin(a, nBytes)[len("0b"):], group, sep)
 }
 
 // Returns the decimal string representation of the bytes
 func BytesToDec(a []byte, nBytes uint) string {
+	// Fast path for numbers that fit in a machine word
+	if trimmed := trimLeadingZeros(a); len(trimmed) <= WORD_BYTES {
+		return strconv.FormatUint(loadUint64(trimmed), 10)
+	}
 	return big.NewInt(0).SetBytes(a).String()
 }
 
+// Returns the decimal string representation of the bytes, with sep between every group of digits
+func BytesToDecGrouped(a []byte, nBytes uint, group uint, sep string) string {
+	if group == 0 {
+		return BytesToDec(a, nBytes)
+	}
+	return GroupDigits(BytesToDec(a, nBytes), group, sep)
+}
+
 // Returns the hexadecimal string representation of the bytes
 func BytesToHex(a []byte, nBytes uint) string {
-	// Pad with zeros up to nBytes or len(a), whichever is greater
+	const digits = "0123456789abcdef"
+	// Pad with zeros up to nBytes or len(a), whichever is greater, with the padding capped like in PrependZeros
+	padding := uint(0)
 	if nBytes > Ulen(a) {
-		a = PrependZeros(a, nBytes-Ulen(a))
+		padding = Uintmin(nBytes-Ulen(a), MAX_SLICE_SIZE)
+	}
+	var builder strings.Builder
+	builder.Grow(len("0x") + 2*int(padding+Ulen(a)))
+	builder.WriteString("0x")
+	for i := uint(0); i < padding; i++ {
+		builder.WriteString("00")
+	}
+	for _, b := range a {
+		builder.WriteByte(digits[b>>4])
+		builder.WriteByte(digits[b&0xf])
 	}
-	return "0x" + hex.EncodeToString(a)
+	return builder.String()
+}
+
+// Returns the hexadecimal string representation of the bytes, with sep between every group of digits
+func BytesToHexGrouped(a []byte, nBytes uint, group uint, sep string) string {
+	if group == 0 {
+		return BytesToHex(a, nBytes)
+	}
+	return "0x" + GroupDigits(BytesToHex(a, nBytes)[len("0x"):], group, sep)
 }
 
 // Returns the input with the byte order reversed
@@ -225,13 +273,39 @@ func Clz(input []byte) []byte {
 
 // Returns whether the two are equivalent except for any leading zeros
 func Equivalent(left, right []byte) bool {
-	left, right = PadToEqualSize(left, right)
-	for i, li := range left {
-		if li != right[i] {
-			return false
+	return bytes.Equal(trimLeadingZeros(left), trimLeadingZeros(right))
+}
+
+// Pads the big-endian number to exactly nBytes, or returns an error if it does not fit
+func FitExactly(a []byte, nBytes uint) ([]byte, error) {
+	fitted, truncated := FitToSize(a, nBytes)
+	if truncated {
+		return nil, fmt.Errorf("%w: %s does not fit in %d bits", ErrOverflow, BytesToHex(trimLeadingZeros(a), 0), 8*nBytes)
+	}
+	return fitted, nil
+}
+
+// Pads or truncates the big-endian number to exactly nBytes, and reports whether any non-zero bytes were cut off
+func FitToSize(a []byte, nBytes uint) ([]byte, bool) {
+	if nBytes > Ulen(a) {
+		return PrependZeros(a, nBytes-Ulen(a)), false
+	}
+	return Truncate(a, nBytes), firstNonZeroIndex(a) < Ulen(a)-nBytes
+}
+
+// Inserts sep between every group of digits, counting from the right. A group size of 0 disables grouping
+func GroupDigits(digits string, group uint, sep string) string {
+	if group == 0 || Ulen([]byte(digits)) <= group {
+		return digits
+	}
+	var builder strings.Builder
+	for i, digit := range []byte(digits) {
+		if i > 0 && uint(len(digits)-i)%group == 0 {
+			builder.WriteString(sep)
 		}
+		builder.WriteByte(digit)
 	}
-	return true
+	return builder.String()
 }
 
 // Returns whether the left argument represents a strictly greater number than the right argument
@@ -256,22 +330,16 @@ func LeftIsGreater(left, right []byte) bool {
 func LeftIsGreaterOrEqual(left, right []byte) bool {
 	left, right = PadToEqualSize(left, right)
 
-	// Zeros are equal
-	if len(left) == 0 {
-		return true
-	}
-
 	// On the first byte that differs we know the result
 	for i, l := range left {
 		r := right[i]
-		if l >= r {
-			return true
-		} else if l < r {
-			return false
+		if l != r {
+			return l > r
 		}
 	}
 
-	panic("Invalid result for LeftIsGreaterOrEqual")
+	// They are equal
+	return true
 }
 
 // Returns the number of bits needed to represent the input
@@ -289,12 +357,12 @@ func NibbleSwap(input []byte) []byte {
 
 // Returns ~a
 func Not(input []byte) []byte {
-	return UnaryOp(input, func(ai byte) byte { return byte(0xff - int(ai)) })
+	return WordOp(input, nil, func(ai, _ uint64) uint64 { return ^ai })
 }
 
 // Returns a OR b
 func Or(a, b []byte) []byte {
-	return BinaryOp(a, b, func(ai, bi byte) byte { return ai | bi })
+	return WordOp(a, b, func(ai, bi uint64) uint64 { return ai | bi })
 }
 
 // Returns slices of equal length representing the same big-endian numbers as a and b
@@ -312,11 +380,11 @@ func PadToEqualSize(a, b []byte) ([]byte, []byte) {
 
 // Returns the popcount of the input
 func Popcount(input []byte) []byte {
-	answerInt := uint64(0)
-	for _, b := range input {
-		answerInt += uint64(bits.OnesCount(uint(b)))
+	answerInt := 0
+	for k := 0; k < nWords(len(input)); k++ {
+		answerInt += bits.OnesCount64(loadWord(input, k))
 	}
-	return uint64ToBytes(answerInt)
+	return uint64ToBytes(uint64(answerInt))
 }
 
 // Prepends n zeros to the slice
@@ -349,63 +417,78 @@ func ShiftLeft(a, b []byte) []byte {
 	if len(a) == 0 {
 		return a
 	}
-	b = trimLeadingZeros(b)
 	nBits, err := bytesToUint64(b)
-	if err != nil || nBits >= nbitsAsUint64(a) {
+	if err != nil || nBits >= 8*U64len(a) {
 		return Zeros(Ulen(a))
 	}
+	answer := make([]byte, len(a))
 
-	// First do the part of the shift that is divisible by 8
-	nBytes := nBits / 8
-
-	// Copy over starting from the end of the slice
-	for i := uint64(0); i < U64len(a)-nBytes; i++ {
-		dest := U64len(a) - 1 - i
-		src := dest - nBytes
-		a[dest] = a[src]
-	}
-
-	// Fill the front with zeros
-	for i := uint64(0); i < nBytes; i++ {
-		a[i] = 0
+	// Fast path for numbers that fit in a machine word
+	if len(a) <= WORD_BYTES {
+		storeUint64(answer, loadUint64(a)>>nBits)
+		return answer
 	}
 
-	// Then do the remainder
-	nBits = nBits % 8
-	for i := uint64(0); i < nBits; i++ {
-		carry := false
-		for j, ai := range a {
-			a[j] >>= 1
-			if carry {
-				a[j] += 1 << 7
-			}
-			carry = ai&1 == 1
-		}
+	// Each word of the answer takes its low bits from one word of a and its high bits from the next.
+	// Shifting a uint64 by 64 gives 0, so a shift by a whole number of words needs no special case
+	wordShift, bitShift := int(nBits/64), nBits%64
+	for k := 0; k < nWords(len(a)); k++ {
+		low := loadWord(a, k+wordShift) >> bitShift
+		high := loadWord(a, k+wordShift+1) << (64 - bitShift)
+		storeWord(answer, k, low|high)
 	}
-
-	return a
+	return answer
 }
 
 // Returns a << b
 func ShiftRight(a, b []byte) []byte {
-	return BitstringReverse(ShiftLeft(BitstringReverse(a), b))
+	if len(a) == 0 {
+		return a
+	}
+	nBits, err := bytesToUint64(b)
+	if err != nil || nBits >= 8*U64len(a) {
+		return Zeros(Ulen(a))
+	}
+	answer := make([]byte, len(a))
+
+	// Fast path for numbers that fit in a machine word. Bits shifted past the length of a are dropped by the store
+	if len(a) <= WORD_BYTES {
+		storeUint64(answer, loadUint64(a)<<nBits)
+		return answer
+	}
+
+	// Each word of the answer takes its high bits from one word of a and its low bits from the previous
+	wordShift, bitShift := int(nBits/64), nBits%64
+	for k := 0; k < nWords(len(a)); k++ {
+		high := loadWord(a, k-wordShift) << bitShift
+		low := loadWord(a, k-wordShift-1) >> (64 - bitShift)
+		storeWord(answer, k, low|high)
+	}
+	return answer
 }
 
-// Parses the input string to a byte array
-func StringToBytes(a string) ([]byte, bool) {
+// Parses the input string to a byte array. It can be a number, or text as described in TextToBytes
+func StringToBytes(a string) ([]byte, error) {
+	if IsTextLiteral(a) {
+		return TextToBytes(a)
+	}
 	resultInt, ok := big.NewInt(0).SetString(a, 0)
-	result := []byte{}
-	if ok {
-		result = resultInt.Bytes()
+	if !ok {
+		return []byte{}, fmt.Errorf("%w '%s'", ErrInvalidNumber, a)
 	}
-	return result, ok
+	return resultInt.Bytes(), nil
 }
 
-// Subtracts b from a, both representing big-endian numbers
+// Subtracts b from a, both representing big-endian numbers. The result wraps around at the length of the longer input
 func Subtract(a, b []byte) []byte {
-	a, b = PadToEqualSize(a, b)
-	subtracted := Add(a, TwosComplement(b))
-	return Truncate(subtracted, Ulen(a))
+	answer := make([]byte, Intmax(len(a), len(b)))
+	borrow := uint64(0)
+	for k := 0; k < nWords(len(answer)); k++ {
+		var difference uint64
+		difference, borrow = bits.Sub64(loadWord(a, k), loadWord(b, k), borrow)
+		storeWord(answer, k, difference)
+	}
+	return answer
 }
 
 // Returns the last n bytes in a
@@ -423,9 +506,7 @@ func TwosComplement(input []byte) []byte {
 	if len(input) == 0 {
 		return input
 	}
-
-	// Otherwise -a is ~a+1
-	return Truncate(Add(Not(input), []byte{1}), Ulen(input))
+	return Subtract(Zeros(Ulen(input)), input)
 }
 
 // Returns the unsigned length of the input
@@ -449,7 +530,7 @@ func U64len(a []byte) uint64 {
 
 // Returns a XOR b
 func Xor(a, b []byte) []byte {
-	return BinaryOp(a, b, func(ai, bi byte) byte { return ai ^ bi })
+	return WordOp(a, b, func(ai, bi uint64) uint64 { return ai ^ bi })
 }
 
 // Returns zero-initialized byte slice of length n
@@ -2,6 +2,7 @@ package ops
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"reflect"
 	"runtime/debug"
@@ -10,12 +11,78 @@ import (
 	"testing/quick"
 )
 
+// Operands of the benchmarks, for widths on both sides of the 64-bit fast paths
+var benchmarkOperands = []struct {
+	name string
+	a    []byte
+	b    []byte
+}{
+	{"8bit", []byte{0x4a}, []byte{0x05}},
+	{"32bit", []byte{0x4a, 0xef, 0xae, 0x18}, []byte{0x00, 0x00, 0x00, 0x0d}},
+	{"64bit", []byte{0x4a, 0xef, 0xae, 0x18, 0x77, 0x12, 0x34, 0x56}, []byte{0, 0, 0, 0, 0, 0, 0, 0x0d}},
+	{"256bit", bytes.Repeat([]byte{0x4a, 0xef, 0xae, 0x18}, 8), append(make([]byte, 31), 0x8d)},
+}
+
+func benchmarkBinary(b *testing.B, f func(a, b []byte) []byte) {
+	for _, operands := range benchmarkOperands {
+		b.Run(operands.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				f(operands.a, operands.b)
+			}
+		})
+	}
+}
+
+func benchmarkFormat(b *testing.B, f func(a []byte, nBytes uint) string) {
+	for _, operands := range benchmarkOperands {
+		b.Run(operands.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				f(operands.a, Ulen(operands.a))
+			}
+		})
+	}
+}
+
 func check(t *testing.T, f interface{}) {
 	if err := quick.Check(f, nil); err != nil {
 		t.Errorf("%v\n\nStack:%s\n", err, debug.Stack())
 	}
 }
 
+func BenchmarkAdd(b *testing.B) {
+	benchmarkBinary(b, Add)
+}
+
+func BenchmarkAnd(b *testing.B) {
+	benchmarkBinary(b, And)
+}
+
+func BenchmarkBytesToBin(b *testing.B) {
+	benchmarkFormat(b, BytesToBin)
+}
+
+func BenchmarkBytesToDec(b *testing.B) {
+	benchmarkFormat(b, BytesToDec)
+}
+
+func BenchmarkBytesToHex(b *testing.B) {
+	benchmarkFormat(b, BytesToHex)
+}
+
+func BenchmarkShiftLeft(b *testing.B) {
+	benchmarkBinary(b, ShiftLeft)
+}
+
+func BenchmarkShiftRight(b *testing.B) {
+	benchmarkBinary(b, ShiftRight)
+}
+
+func BenchmarkSubtract(b *testing.B) {
+	benchmarkBinary(b, Subtract)
+}
+
 func TestAdd(t *testing.T) {
 	var vector = []struct {
 		a    []byte
@@ -173,6 +240,60 @@ func TestAnd(t *testing.T) {
 	})
 }
 
+func TestBitRuler(t *testing.T) {
+	var vector = []struct {
+		nBytes uint
+		group  uint
+		sep    string
+		want   string
+	}{
+		{
+			1,
+			0,
+			"_",
+			"  7   3   ",
+		},
+		{
+			2,
+			4,
+			"_",
+			"  15   11   7    3   ",
+		},
+		{
+			2,
+			8,
+			" ",
+			"  15  11   7   3   ",
+		},
+		{
+			2,
+			4,
+			"0",
+			"  15   11   7    3   ",
+		},
+		{
+			1,
+			2,
+			"1.0",
+			"  7         3      ",
+		},
+	}
+	for _, tt := range vector {
+		testname := fmt.Sprintf("%v,%v,%v\n", tt.nBytes, tt.group, tt.sep)
+		t.Run(testname, func(t *testing.T) {
+			have := BitRuler(tt.nBytes, tt.group, tt.sep)
+			if have != tt.want {
+				t.Errorf("Want %q, have %q\n", tt.want, have)
+			}
+		})
+	}
+
+	// Property: the ruler is exactly as wide as the binary representation
+	check(t, func(nBytes, group uint8) bool {
+		return len(BitRuler(uint(nBytes), uint(group), "_")) == len(BytesToBinGrouped(Zeros(uint(nBytes)), uint(nBytes), uint(group), "_"))
+	})
+}
+
 func TestBytesToHex(t *testing.T) {
 	var vector = []struct {
 		input  []byte
@@ -469,6 +590,38 @@ func TestClz(t *testing.T) {
 	}
 }
 
+func TestGroupDigits(t *testing.T) {
+	var vector = []struct {
+		digits string
+		group  uint
+		sep    string
+		want   string
+	}{
+		{"", 4, "_", ""},
+		{"1877", 0, "_", "1877"},
+		{"1877", 4, "_", "1877"},
+		{"1877abcd", 4, "_", "1877_abcd"},
+		{"0001100001110111", 4, "_", "0001_1000_0111_0111"},
+		{"1234567", 3, ",", "1,234,567"},
+		{"123456", 3, ",", "123,456"},
+		{"12345", 2, " ", "1 23 45"},
+	}
+	for _, tt := range vector {
+		testname := fmt.Sprintf("%v,%v,%v\n", tt.digits, tt.group, tt.sep)
+		t.Run(testname, func(t *testing.T) {
+			have := GroupDigits(tt.digits, tt.group, tt.sep)
+			if have != tt.want {
+				t.Errorf("Want %v, have %v\n", tt.want, have)
+			}
+		})
+	}
+
+	// Property: removing the separators gives back the digits
+	check(t, func(digits string, group uint8) bool {
+		return strings.ReplaceAll(GroupDigits(digits, uint(group), "\x00"), "\x00", "") == digits
+	})
+}
+
 func TestNbits(t *testing.T) {
 	var vector = []struct {
 		input []byte
@@ -723,6 +876,13 @@ func TestShiftLeft(t *testing.T) {
 	check(t, func(a, b []byte) bool {
 		return LeftIsGreaterOrEqual(a, ShiftLeft(a, b))
 	})
+
+	// Property: ShiftLeft leaves the input alone
+	check(t, func(a, b []byte) bool {
+		original := append([]byte{}, a...)
+		ShiftLeft(a, b)
+		return bytes.Equal(a, original)
+	})
 }
 
 func TestShiftRight(t *testing.T) {
@@ -831,13 +991,20 @@ func TestStringToBytes(t *testing.T) {
 	for _, tt := range vector {
 		testname := fmt.Sprintf("%v\n", tt.input)
 		t.Run(testname, func(t *testing.T) {
-			have, ok := StringToBytes(tt.input)
-			if !bytes.Equal(have, tt.want) || !ok {
-				t.Errorf("Want %v, have %v (ok: %v)", tt.want, have, ok)
+			have, err := StringToBytes(tt.input)
+			if !bytes.Equal(have, tt.want) || err != nil {
+				t.Errorf("Want %v, have %v (error: %v)", tt.want, have, err)
 			}
 		})
 	}
 
+	// Invalid numbers give ErrInvalidNumber
+	for _, input := range []string{"", "0xzz", "12abc", "popcount"} {
+		if _, err := StringToBytes(input); !errors.Is(err, ErrInvalidNumber) {
+			t.Errorf("Want ErrInvalidNumber for %q, have %v", input, err)
+		}
+	}
+
 	// Property: StringsToBytes does not crash
 	check(t, func(a string) bool {
 		StringToBytes(a)
@@ -0,0 +1,217 @@
+package ops
+
+import (
+	"encoding/base32"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// Digits of numbers in bases 2 to 64, in order. Up to base 62 they are the digits used by big.Int, and bases up to
+// 36 are case-insensitive
+const RADIX_DIGITS = "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ-_"
+
+const (
+	MIN_RADIX = 2
+	MAX_RADIX = 64
+)
+
+// Alphabets of the encodings which treat the bytes as one big number
+const (
+	BASE58_ALPHABET    = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+	CROCKFORD_ALPHABET = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+)
+
+// A named text encoding of bytes, e.g. base64
+type Encoding struct {
+	// Name used to select the encoding, e.g. with --in-base or --columns
+	Name string
+
+	// Human readable description, shown in the help text
+	Description string
+
+	Encode func(a []byte) string
+	Decode func(text string) ([]byte, error)
+}
+
+// The named encodings, in the order they are listed
+var ENCODINGS = []Encoding{
+	{
+		Name:        "base32",
+		Description: "RFC 4648 base32 of the bytes, with padding",
+		Encode:      base32.StdEncoding.EncodeToString,
+		Decode: func(text string) ([]byte, error) {
+			return base32.StdEncoding.DecodeString(strings.ToUpper(text))
+		},
+	},
+	{
+		Name:        "crockford32",
+		Description: "Crockford's base32 of the number, without leading zeros",
+		Encode: func(a []byte) string {
+			return strings.ToUpper(bytesToDigits(a, CROCKFORD_ALPHABET))
+		},
+		Decode: decodeCrockford,
+	},
+	{
+		Name:        "base58",
+		Description: "Base58 of the bytes with the Bitcoin alphabet, where each leading zero byte is a 1",
+		Encode:      encodeBase58,
+		Decode:      decodeBase58,
+	},
+	{
+		Name:        "base64",
+		Description: "RFC 4648 base64 of the bytes, with padding",
+		Encode:      base64.StdEncoding.EncodeToString,
+		Decode:      base64.StdEncoding.DecodeString,
+	},
+	{
+		Name:        "base64url",
+		Description: "RFC 4648 URL-safe base64 of the bytes, without padding",
+		Encode:      base64.RawURLEncoding.EncodeToString,
+		Decode: func(text string) ([]byte, error) {
+			return base64.RawURLEncoding.DecodeString(strings.TrimRight(text, "="))
+		},
+	},
+}
+
+// Returns the big-endian number written with the digits of the alphabet, where the base is the size of the alphabet
+func bytesToDigits(a []byte, alphabet string) string {
+	number := big.NewInt(0).SetBytes(a)
+	if number.Sign() == 0 {
+		return alphabet[:1]
+	}
+	base := big.NewInt(int64(len(alphabet)))
+	digits := []byte{}
+	digit := big.NewInt(0)
+	for number.Sign() > 0 {
+		number.QuoRem(number, base, digit)
+		digits = append(digits, alphabet[digit.Int64()])
+	}
+	for i, j := 0, len(digits)-1; i < j; i, j = i+1, j-1 {
+		digits[i], digits[j] = digits[j], digits[i]
+	}
+	return string(digits)
+}
+
+// Parses base58, where each leading 1 is a zero byte
+func decodeBase58(text string) ([]byte, error) {
+	zeros := len(text) - len(strings.TrimLeft(text, "1"))
+	if zeros == len(text) {
+		return make([]byte, zeros), nil
+	}
+	number, err := digitsToBytes(text[zeros:], BASE58_ALPHABET)
+	if err != nil {
+		return nil, err
+	}
+	return append(make([]byte, zeros), number...), nil
+}
+
+// Parses Crockford's base32, which ignores case and hyphens and reads I and L as 1 and O as 0
+func decodeCrockford(text string) ([]byte, error) {
+	normalized := strings.NewReplacer("-", "", "I", "1", "L", "1", "O", "0").Replace(strings.ToUpper(text))
+	return digitsToBytes(normalized, CROCKFORD_ALPHABET)
+}
+
+// Parses a big-endian number written with the digits of the alphabet, where the base is the size of the alphabet
+func digitsToBytes(text string, alphabet string) ([]byte, error) {
+	if text == "" {
+		return nil, fmt.Errorf("no digits")
+	}
+	number := big.NewInt(0)
+	base := big.NewInt(int64(len(alphabet)))
+	for i := 0; i < len(text); i++ {
+		digit := strings.IndexByte(alphabet, text[i])
+		if digit < 0 {
+			return nil, fmt.Errorf("'%c' is not a digit in base %d", text[i], len(alphabet))
+		}
+		number.Mul(number, base).Add(number, big.NewInt(int64(digit)))
+	}
+	return number.Bytes(), nil
+}
+
+// Returns the bytes in base58, where each leading zero byte is a 1
+func encodeBase58(a []byte) string {
+	zeros := len(a) - len(trimLeadingZeros(a))
+	if zeros == len(a) {
+		return strings.Repeat("1", zeros)
+	}
+	return strings.Repeat("1", zeros) + bytesToDigits(a[zeros:], BASE58_ALPHABET)
+}
+
+// Parses a number written with the digits of a custom alphabet, where the base is the number of digits. The digits
+// are case-sensitive, and underscores between digits are ignored unless _ is one of them
+func AlphabetToBytes(text string, digits string) ([]byte, error) {
+	written := text
+	if !strings.Contains(digits, "_") {
+		written = strings.ReplaceAll(written, "_", "")
+	}
+	bytes, err := digitsToBytes(written, digits)
+	if err != nil {
+		return nil, fmt.Errorf("%w '%s': %v", ErrInvalidNumber, text, err)
+	}
+	return bytes, nil
+}
+
+// Returns the number written with the digits of a custom alphabet, where the base is the number of digits
+func BytesToAlphabet(a []byte, digits string) string {
+	return bytesToDigits(a, digits)
+}
+
+// Returns the number in the given base from 2 to 64, with the digits in RADIX_DIGITS
+func BytesToRadix(a []byte, base uint) string {
+	return bytesToDigits(a, RADIX_DIGITS[:base])
+}
+
+// Returns an error if the alphabet can not be used as the digits of a base, which takes 2 to 64 different printable
+// ASCII characters other than space
+func CheckAlphabet(alphabet string) error {
+	if len(alphabet) < MIN_RADIX || len(alphabet) > MAX_RADIX {
+		return fmt.Errorf("it has %d digits, expected %d to %d", len(alphabet), MIN_RADIX, MAX_RADIX)
+	}
+	for i := 0; i < len(alphabet); i++ {
+		switch {
+		case alphabet[i] <= ' ' || alphabet[i] > '~':
+			return fmt.Errorf("digit %d is not a printable ASCII character", i)
+		case strings.IndexByte(alphabet, alphabet[i]) < i:
+			return fmt.Errorf("'%c' is in it more than once", alphabet[i])
+		}
+	}
+	return nil
+}
+
+// Returns the names of the encodings
+func EncodingNames() []string {
+	names := []string{}
+	for _, encoding := range ENCODINGS {
+		names = append(names, encoding.Name)
+	}
+	return names
+}
+
+// Returns the encoding with the given name
+func LookupEncoding(name string) (Encoding, bool) {
+	for _, encoding := range ENCODINGS {
+		if encoding.Name == name {
+			return encoding, true
+		}
+	}
+	return Encoding{}, false
+}
+
+// Parses a number written in the given base from 2 to 64, with the digits in RADIX_DIGITS. Bases up to 36 are
+// case-insensitive, and underscores between digits are ignored except in base 64, where _ is a digit
+func RadixToBytes(text string, base uint) ([]byte, error) {
+	digits := text
+	if base <= 36 {
+		digits = strings.ToLower(digits)
+	}
+	if base < MAX_RADIX {
+		digits = strings.ReplaceAll(digits, "_", "")
+	}
+	bytes, err := digitsToBytes(digits, RADIX_DIGITS[:base])
+	if err != nil {
+		return nil, fmt.Errorf("%w '%s': %v", ErrInvalidNumber, text, err)
+	}
+	return bytes, nil
+}
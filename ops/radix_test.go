@@ -0,0 +1,184 @@
+package ops
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestAlphabet(t *testing.T) {
+	var vector = []struct {
+		name   string
+		input  []byte
+		digits string
+		want   string
+	}{
+		{"zero", []byte{}, "XYZ", "X"},
+		{"upper hex", []byte{0x18, 0x77}, "0123456789ABCDEF", "1877"},
+		{"base58 without leading ones", []byte{0x00, 0x01}, BASE58_ALPHABET, "2"},
+		{"letters", []byte{0x05}, "ab", "bab"},
+	}
+	for _, tt := range vector {
+		t.Run(tt.name, func(t *testing.T) {
+			have := BytesToAlphabet(tt.input, tt.digits)
+			if have != tt.want {
+				t.Errorf("Want %s, have %s", tt.want, have)
+			}
+			parsed, err := AlphabetToBytes(have, tt.digits)
+			if err != nil || new(big.Int).SetBytes(parsed).Cmp(new(big.Int).SetBytes(tt.input)) != 0 {
+				t.Errorf("Want %v back, have %v (%v)", tt.input, parsed, err)
+			}
+		})
+	}
+
+	// The digits are case-sensitive, and underscores separate digits unless they are digits themselves
+	if _, err := AlphabetToBytes("A", "0123456789abcdef"); !errors.Is(err, ErrInvalidNumber) {
+		t.Errorf("Want ErrInvalidNumber for A with lowercase digits, have %v", err)
+	}
+	if have, err := AlphabetToBytes("b_a_b", "ab"); err != nil || !bytes.Equal(have, []byte{0x05}) {
+		t.Errorf("Want 0x05, have %v (%v)", have, err)
+	}
+	if have, err := AlphabetToBytes("_a", "a_"); err != nil || !bytes.Equal(have, []byte{0x02}) {
+		t.Errorf("Want 0x02, have %v (%v)", have, err)
+	}
+}
+
+func TestCheckAlphabet(t *testing.T) {
+	var vector = []struct {
+		name     string
+		alphabet string
+		valid    bool
+	}{
+		{"default digits", RADIX_DIGITS, true},
+		{"two digits", "ab", true},
+		{"one digit", "a", false},
+		{"too many digits", RADIX_DIGITS + "!", false},
+		{"repeated digit", "0120", false},
+		{"space", "0 1", false},
+		{"not ASCII", "0é", false},
+	}
+	for _, tt := range vector {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := CheckAlphabet(tt.alphabet); (err == nil) != tt.valid {
+				t.Errorf("Want valid=%v, have %v", tt.valid, err)
+			}
+		})
+	}
+}
+
+func TestEncodings(t *testing.T) {
+	var vector = []struct {
+		encoding string
+		input    []byte
+		want     string
+	}{
+		{"base32", []byte("foobar"), "MZXW6YTBOI======"},
+		{"crockford32", []byte{0x00, 0x00}, "0"},
+		{"crockford32", []byte{0x01, 0x00}, "80"},
+		{"crockford32", []byte{0xff, 0xff, 0xff, 0xff}, "3ZZZZZZ"},
+		{"base58", []byte("hello world"), "StV1DL6CwTryKyV"},
+		{"base58", []byte{0x00, 0x00, 0x01}, "112"},
+		{"base58", []byte{0x00}, "1"},
+		{"base64", []byte("foob"), "Zm9vYg=="},
+		{"base64url", []byte{0xfb, 0xff}, "-_8"},
+	}
+	for _, tt := range vector {
+		encoding, ok := LookupEncoding(tt.encoding)
+		if !ok {
+			t.Fatalf("No encoding %s", tt.encoding)
+		}
+		if have := encoding.Encode(tt.input); have != tt.want {
+			t.Errorf("%s(%v): want %s, have %s", tt.encoding, tt.input, tt.want, have)
+		}
+	}
+
+	// Property: decoding gives back the bytes, except for leading zeros in crockford32 which encodes the number
+	check(t, func(a []byte) bool {
+		for _, encoding := range ENCODINGS {
+			decoded, err := encoding.Decode(encoding.Encode(a))
+			want := a
+			if encoding.Name == "crockford32" {
+				want, decoded = trimLeadingZeros(a), trimLeadingZeros(decoded)
+			}
+			if err != nil || !bytes.Equal(decoded, want) {
+				t.Logf("%s: %v decoded to %v (%v)", encoding.Name, a, decoded, err)
+				return false
+			}
+		}
+		return true
+	})
+}
+
+func TestEncodingDecodeVariants(t *testing.T) {
+	var vector = []struct {
+		encoding string
+		input    string
+		want     []byte
+	}{
+		{"base32", "mzxw6ytboi======", []byte("foobar")},
+		{"crockford32", "3zz-zzzz", []byte{0xff, 0xff, 0xff, 0xff}},
+		{"crockford32", "IlOo", []byte{0x84, 0x00}},
+		{"base64url", "-_8=", []byte{0xfb, 0xff}},
+		{"base58", "11", []byte{0x00, 0x00}},
+	}
+	for _, tt := range vector {
+		encoding, _ := LookupEncoding(tt.encoding)
+		if have, err := encoding.Decode(tt.input); err != nil || !bytes.Equal(have, tt.want) {
+			t.Errorf("%s(%s): want %v, have %v (%v)", tt.encoding, tt.input, tt.want, have, err)
+		}
+	}
+	for _, input := range []string{"0OIl", "1+", "11l"} {
+		if _, err := decodeBase58(input); err == nil {
+			t.Errorf("Want an error for base58 %q", input)
+		}
+	}
+}
+
+func TestRadix(t *testing.T) {
+	var vector = []struct {
+		input []byte
+		base  uint
+		want  string
+	}{
+		{[]byte{}, 2, "0"},
+		{[]byte{0x05}, 2, "101"},
+		{[]byte{0x18, 0x77}, 36, "4tz"},
+		{[]byte{0x18, 0x77}, 62, "1D1"},
+		{[]byte{0xff}, 64, "3_"},
+		{[]byte{0x0f}, 64, "f"},
+	}
+	for _, tt := range vector {
+		if have := BytesToRadix(tt.input, tt.base); have != tt.want {
+			t.Errorf("%v in base %d: want %s, have %s", tt.input, tt.base, tt.want, have)
+		}
+	}
+
+	// Up to base 62, the digits are those of big.Int
+	number := big.NewInt(0).SetBytes([]byte{0x12, 0x34, 0x56, 0x78, 0x9a})
+	for base := uint(MIN_RADIX); base <= 62; base++ {
+		if have, want := BytesToRadix(number.Bytes(), base), number.Text(int(base)); have != want {
+			t.Errorf("Base %d: want %s, have %s", base, want, have)
+		}
+		parsed, err := RadixToBytes(number.Text(int(base)), base)
+		if err != nil || !bytes.Equal(parsed, number.Bytes()) {
+			t.Errorf("Base %d: want %v, have %v (%v)", base, number.Bytes(), parsed, err)
+		}
+	}
+
+	// Bases up to 36 ignore case, and underscores separate digits except in base 64
+	if have, err := RadixToBytes("4TZ", 36); err != nil || !bytes.Equal(have, []byte{0x18, 0x77}) {
+		t.Errorf("Want 0x1877, have %v (%v)", have, err)
+	}
+	if have, err := RadixToBytes("1111_1111", 2); err != nil || !bytes.Equal(have, []byte{0xff}) {
+		t.Errorf("Want 0xff, have %v (%v)", have, err)
+	}
+	if have, err := RadixToBytes("3_", 64); err != nil || !bytes.Equal(have, []byte{0xff}) {
+		t.Errorf("Want 0xff, have %v (%v)", have, err)
+	}
+	for _, input := range []string{"", "2", "_"} {
+		if _, err := RadixToBytes(input, 2); !errors.Is(err, ErrInvalidNumber) {
+			t.Errorf("Want ErrInvalidNumber for %q, have %v", input, err)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package ops
+
+import (
+	"fmt"
+	"strings"
+)
+
+// An operation that jco knows about. The table, the help text and everything else that lists
+// operations is driven by the registered operations, so adding an operation is one call to Register
+type Operation struct {
+	// Name used to select the operation, e.g. with --ops
+	Name string
+
+	// Number of operands
+	Arity int
+
+	// Human readable description, shown in the help text. May span several lines
+	Description string
+
+	// Format string for the formula, where %[1]s is the first operand and %[2]s is the second
+	Formula string
+
+	// Operator used for the operation in expressions, e.g. "+", or "" if it can only be called by name
+	Symbol string
+
+	// Whether the operation is also shown with the operands swapped (only meaningful for Arity 2)
+	Swappable bool
+
+	// Implementation, which receives exactly Arity operands
+	Apply func(operands ...[]byte) []byte
+
+	// Whether the operation is left out of the table unless it is selected, e.g. with --ops
+	Optional bool
+
+	// Whether the result is the exact value of an arithmetic expression, like a sum, so that it overflows like in C:
+	// signed results overflow when the signed value does not fit, rather than when there is a carry out of the top bit
+	Arithmetic bool
+
+	// Returns why the result is meaningless for the operands, e.g. because a nibble is not a decimal digit, or nil.
+	// May be nil if every result is meaningful
+	Check func(operands ...[]byte) error
+}
+
+var registry = []Operation{}
+
+// Wraps a check of one number as the Check of a two-operand operation, which checks both operands
+func binaryCheck(check func(a []byte) error) func(operands ...[]byte) error {
+	return func(operands ...[]byte) error {
+		for i, operand := range operands[:2] {
+			if err := check(operand); err != nil {
+				return fmt.Errorf("%s operand: %v", []string{"first", "second"}[i], err)
+			}
+		}
+		return nil
+	}
+}
+
+// Wraps a two-operand function as an Operation implementation
+func binaryOperation(f func(a, b []byte) []byte) func(operands ...[]byte) []byte {
+	return func(operands ...[]byte) []byte { return f(operands[0], operands[1]) }
+}
+
+func init() {
+	Register(Operation{Name: "value", Arity: 1, Description: "The number itself", Formula: "%s ", Apply: unaryOperation(func(a []byte) []byte { return a })})
+	Register(Operation{Name: "not", Arity: 1, Description: "Bitwise NOT", Formula: "~%s ", Symbol: "~", Apply: unaryOperation(Not)})
+	Register(Operation{Name: "twos_complement", Arity: 1, Description: "Two's complement (depends on bit width)", Formula: "twos_complement(%s)", Symbol: "-", Arithmetic: true, Apply: unaryOperation(TwosComplement)})
+	Register(Operation{Name: "popcount", Arity: 1, Description: "Number of bits that are 1", Formula: "popcount(%s)", Apply: unaryOperation(Popcount)})
+	Register(Operation{Name: "clz", Arity: 1, Description: "Number of leading zeros", Formula: "clz(%s)", Apply: unaryOperation(Clz)})
+	Register(Operation{Name: "nbits", Arity: 1, Description: "Number of bits needed to represent the number", Formula: "nbits(%s)", Apply: unaryOperation(Nbits)})
+	Register(Operation{Name: "reverse_bitstring", Arity: 1, Description: "Interprets the input as a stream of bits, and reverses them.\nEquivalent to reverse_bitorder followed by reverse_byteorder.", Formula: "reverse_bitstring(%s)", Apply: unaryOperation(BitstringReverse)})
+	Register(Operation{Name: "reverse_bitorder", Arity: 1, Description: "Reverses the bit order within each byte    (0b11100011 -> 0b11000111)", Formula: "reverse_bitorder(%s)", Apply: unaryOperation(BitReverse)})
+	Register(Operation{Name: "reverse_byteorder", Arity: 1, Description: "Reverses the byte order", Formula: "reverse_byteorder(%s)", Apply: unaryOperation(ByteReverse)})
+	Register(Operation{Name: "reverse_nibbleorder", Arity: 1, Description: "Reverses the nibble order within each byte (0xab -> 0xba)", Formula: "reverse_nibbleorder(%s)", Apply: unaryOperation(NibbleSwap)})
+	Register(Operation{Name: "bcd", Arity: 1, Description: "Packed BCD of the decimal value (1234 -> 0x1234)", Formula: "bcd(%s)", Apply: unaryOperation(ToBCD), Optional: true})
+	Register(Operation{Name: "from_bcd", Arity: 1, Description: "Decimal value of packed BCD, one digit per nibble (0x1234 -> 1234)", Formula: "from_bcd(%s)", Apply: unaryOperation(FromPackedBCD), Optional: true, Check: unaryCheck(CheckPackedBCD)})
+	Register(Operation{Name: "from_unpacked_bcd", Arity: 1, Description: "Decimal value of unpacked BCD, one digit per byte (0x0102 -> 12).\nThe high nibble may be 0x3, so ASCII digits work too.", Formula: "from_unpacked_bcd(%s)", Apply: unaryOperation(FromUnpackedBCD), Optional: true, Check: unaryCheck(CheckUnpackedBCD)})
+
+	Register(Operation{Name: "a", Arity: 2, Description: "The first number", Formula: "      %[1]s", Apply: binaryOperation(func(a, b []byte) []byte { return a })})
+	Register(Operation{Name: "b", Arity: 2, Description: "The second number", Formula: "      %[2]s", Apply: binaryOperation(func(a, b []byte) []byte { return b })})
+	Register(Operation{Name: "add", Arity: 2, Description: "Sum", Formula: "%[1]s  + %[2]s", Symbol: "+", Arithmetic: true, Apply: binaryOperation(Add)})
+	Register(Operation{Name: "or", Arity: 2, Description: "Bitwise OR", Formula: "%[1]s  | %[2]s", Symbol: "|", Apply: binaryOperation(Or)})
+	Register(Operation{Name: "and", Arity: 2, Description: "Bitwise AND", Formula: "%[1]s  & %[2]s", Symbol: "&", Apply: binaryOperation(And)})
+	Register(Operation{Name: "xor", Arity: 2, Description: "Bitwise XOR", Formula: "%[1]s  ^ %[2]s", Symbol: "^", Apply: binaryOperation(Xor)})
+	Register(Operation{Name: "xnor", Arity: 2, Description: "Bitwise XNOR", Formula: "%[1]s ^~ %[2]s", Apply: binaryOperation(func(a, b []byte) []byte { return Xor(a, Not(b)) })})
+	Register(Operation{Name: "sub", Arity: 2, Description: "Difference (wraps around, depends on bit width)", Formula: "%[1]s  - %[2]s", Symbol: "-", Swappable: true, Arithmetic: true, Apply: binaryOperation(Subtract)})
+	Register(Operation{Name: "andnot", Arity: 2, Description: "Clears the bits in the first number that are set in the second", Formula: "%[1]s &~ %[2]s", Swappable: true, Apply: binaryOperation(func(a, b []byte) []byte { return And(a, Not(b)) })})
+	Register(Operation{Name: "shr", Arity: 2, Description: "Logical shift right", Formula: "%[1]s >> %[2]s", Symbol: ">>", Swappable: true, Apply: binaryOperation(ShiftLeft)})
+	Register(Operation{Name: "shl", Arity: 2, Description: "Shift left", Formula: "%[1]s << %[2]s", Symbol: "<<", Swappable: true, Arithmetic: true, Apply: binaryOperation(ShiftRight)})
+	Register(Operation{Name: "bcd_add", Arity: 2, Description: "Packed BCD sum with decimal adjust, like DAA", Formula: "bcd_add(%[1]s, %[2]s)", Apply: binaryOperation(BCDAdd), Optional: true, Check: binaryCheck(CheckPackedBCD)})
+	Register(Operation{Name: "bcd_sub", Arity: 2, Description: "Packed BCD difference with decimal adjust, like DAS (wraps around, depends on bit width)", Formula: "bcd_sub(%[1]s, %[2]s)", Swappable: true, Apply: binaryOperation(BCDSubtract), Optional: true, Check: binaryCheck(CheckPackedBCD)})
+}
+
+// Wraps a check of one number as the Check of a one-operand operation
+func unaryCheck(check func(a []byte) error) func(operands ...[]byte) error {
+	return func(operands ...[]byte) error { return check(operands[0]) }
+}
+
+// Wraps a one-operand function as an Operation implementation
+func unaryOperation(f func(a []byte) []byte) func(operands ...[]byte) []byte {
+	return func(operands ...[]byte) []byte { return f(operands[0]) }
+}
+
+// Returns the names of the registered operations with the given arity that are shown when no operations are selected,
+// in registration order
+func DefaultOperationNames(arity int) []string {
+	names := []string{}
+	for _, op := range Operations(arity) {
+		if !op.Optional {
+			names = append(names, op.Name)
+		}
+	}
+	return names
+}
+
+// Returns the registered operation with the given name and arity
+func LookupOperation(name string, arity int) (Operation, bool) {
+	for _, op := range registry {
+		if op.Name == name && op.Arity == arity {
+			return op, true
+		}
+	}
+	return Operation{}, false
+}
+
+// Returns the registered operation with the given symbol and arity
+func LookupSymbol(symbol string, arity int) (Operation, bool) {
+	for _, op := range registry {
+		if op.Symbol != "" && op.Symbol == symbol && op.Arity == arity {
+			return op, true
+		}
+	}
+	return Operation{}, false
+}
+
+// Returns the registered operations with the given arity, in registration order
+func Operations(arity int) []Operation {
+	operations := []Operation{}
+	for _, op := range registry {
+		if op.Arity == arity {
+			operations = append(operations, op)
+		}
+	}
+	return operations
+}
+
+// Returns the names of the registered operations with the given arity, in registration order
+func OperationNames(arity int) []string {
+	names := []string{}
+	for _, op := range Operations(arity) {
+		names = append(names, op.Name)
+	}
+	return names
+}
+
+// Adds an operation to the registry. Registering the same name and arity twice is a programming error
+func Register(op Operation) {
+	if _, exists := LookupOperation(op.Name, op.Arity); exists {
+		panic(fmt.Sprintf("Operation %s with arity %d is already registered", op.Name, op.Arity))
+	}
+	if strings.ContainsAny(op.Name, ", ") {
+		panic(fmt.Sprintf("Operation name '%s' can not be used with --ops", op.Name))
+	}
+	registry = append(registry, op)
+}
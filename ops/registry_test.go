@@ -0,0 +1,39 @@
+package ops
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestRegistry(t *testing.T) {
+	for _, arity := range []int{1, 2} {
+		for _, op := range Operations(arity) {
+			t.Run(fmt.Sprintf("%s/%d", op.Name, arity), func(t *testing.T) {
+				// The formula must mention every operand
+				formula := fmt.Sprintf(op.Formula, "A", "B")
+				if arity == 1 {
+					formula = fmt.Sprintf(op.Formula, "A")
+				}
+				if strings.Contains(formula, "%!") {
+					t.Errorf("Bad formula %v\n", op.Formula)
+				}
+
+				// The implementation must accept exactly arity operands
+				operands := [][]byte{}
+				for i := 0; i < arity; i++ {
+					operands = append(operands, []byte{0x12, 0x34})
+				}
+				op.Apply(operands...)
+				if op.Check != nil {
+					op.Check(operands...)
+				}
+
+				// Lookups must find it again
+				if found, ok := LookupOperation(op.Name, arity); !ok || found.Name != op.Name {
+					t.Errorf("Lookup failed for %v\n", op.Name)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package ops
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// The largest Unicode codepoint
+const MAX_CODEPOINT = 0x10ffff
+
+// Simple escape sequences in C literals and the bytes they stand for
+var C_ESCAPES = map[byte]byte{
+	'a':  0x07,
+	'b':  0x08,
+	'f':  0x0c,
+	'n':  '\n',
+	'r':  '\r',
+	't':  '\t',
+	'v':  0x0b,
+	'\\': '\\',
+	'\'': '\'',
+	'"':  '"',
+	'?':  '?',
+}
+
+// Returns the bytes that the text between the quotes of a C literal stands for. The quote may only appear escaped
+func unescapeC(text string, quote byte) ([]byte, error) {
+	bytes := []byte{}
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		if c == quote {
+			return nil, fmt.Errorf("unescaped %c", quote)
+		}
+		if c != '\\' {
+			bytes = append(bytes, c)
+			continue
+		}
+		i++
+		if i >= len(text) {
+			return nil, fmt.Errorf("unfinished escape at the end")
+		}
+		if b, ok := C_ESCAPES[text[i]]; ok {
+			bytes = append(bytes, b)
+			continue
+		}
+
+		// Numeric escapes: up to 3 octal digits, any number of hex digits after \x, or a codepoint after \u or \U
+		digitsOf := func(digits string, max int) string {
+			end := i
+			for end < len(text) && end-i < max && strings.IndexByte(digits, text[end]) >= 0 {
+				end++
+			}
+			return text[i:end]
+		}
+		switch {
+		case '0' <= text[i] && text[i] <= '7':
+			octal := digitsOf("01234567", 3)
+			number, _ := strconv.ParseUint(octal, 8, 16)
+			if number > 0xff {
+				return nil, fmt.Errorf("\\%s does not fit in a byte", octal)
+			}
+			bytes = append(bytes, byte(number))
+			i += len(octal) - 1
+		case text[i] == 'x':
+			i++
+			hex := digitsOf("0123456789abcdefABCDEF", len(text))
+			number, err := strconv.ParseUint(hex, 16, 8)
+			if err != nil {
+				return nil, fmt.Errorf("\\x%s is not a byte", hex)
+			}
+			bytes = append(bytes, byte(number))
+			i += len(hex) - 1
+		case text[i] == 'u' || text[i] == 'U':
+			length := map[byte]int{'u': 4, 'U': 8}[text[i]]
+			i++
+			hex := digitsOf("0123456789abcdefABCDEF", length)
+			codepoint, _ := strconv.ParseUint(hex, 16, 32)
+			if len(hex) != length || codepoint > MAX_CODEPOINT || !utf8.ValidRune(rune(codepoint)) {
+				return nil, fmt.Errorf("\\%c%s is not a codepoint", text[i-1], hex)
+			}
+			bytes = append(bytes, string(rune(codepoint))...)
+			i += len(hex) - 1
+		default:
+			return nil, fmt.Errorf("unknown escape \\%c", text[i])
+		}
+	}
+	return bytes, nil
+}
+
+// Returns the bytes as ASCII text, with a dot for each byte that is not a printable ASCII character. With latin1, bytes
+// from 0xa0 to 0xff are shown as the Latin-1 characters they stand for instead
+func BytesToASCII(a []byte, latin1 bool) string {
+	var builder strings.Builder
+	for _, b := range a {
+		switch {
+		case 0x20 <= b && b <= 0x7e:
+			builder.WriteByte(b)
+		case latin1 && b >= 0xa0:
+			builder.WriteRune(rune(b))
+		default:
+			builder.WriteByte('.')
+		}
+	}
+	return builder.String()
+}
+
+// Returns the bytes as a C string literal, with escapes for quotes, backslashes and bytes that are not printable
+// ASCII. A hex escape is followed by "" if the next character is a hex digit, since it would otherwise be part of
+// the escape
+func BytesToCString(a []byte) string {
+	names := map[byte]byte{}
+	for name, b := range C_ESCAPES {
+		if name != '\'' && name != '?' {
+			names[b] = name
+		}
+	}
+	var builder strings.Builder
+	builder.WriteByte('"')
+	afterHex := false
+	for _, b := range a {
+		name, hasName := names[b]
+		switch {
+		case hasName:
+			builder.WriteByte('\\')
+			builder.WriteByte(name)
+			afterHex = false
+		case b < 0x20 || b > 0x7e:
+			fmt.Fprintf(&builder, "\\x%02x", b)
+			afterHex = true
+		default:
+			if afterHex && strings.IndexByte("0123456789abcdefABCDEF", b) >= 0 {
+				builder.WriteString(`""`)
+			}
+			builder.WriteByte(b)
+			afterHex = false
+		}
+	}
+	builder.WriteByte('"')
+	return builder.String()
+}
+
+// Returns whether the text is a character literal like 'A', a string literal like "abc" or a codepoint like U+1F600
+// rather than a number
+func IsTextLiteral(text string) bool {
+	return strings.HasPrefix(text, "'") || strings.HasPrefix(text, `"`) || strings.HasPrefix(strings.ToUpper(text), "U+")
+}
+
+// Parses a character literal like 'A', a string literal like "abc" or a codepoint like U+1F600 into big-endian bytes.
+// String literals give the UTF-8 bytes of the text between the quotes, in order, with the escapes of C: \n, \x41,
+// \101, \u00e9 and so on. A character literal with one character gives its codepoint like a rune literal in Go, so
+// 'é' gives 0xe9, and one with several ASCII characters or escaped bytes gives the bytes like C's multi-character
+// constants. A codepoint gives its number, like U+0041 gives 0x41
+func TextToBytes(text string) ([]byte, error) {
+	if strings.HasPrefix(strings.ToUpper(text), "U+") {
+		digits := text[len("U+"):]
+		codepoint, err := strconv.ParseUint(digits, 16, 32)
+		if err != nil || len(digits) < 1 || codepoint > MAX_CODEPOINT {
+			return nil, fmt.Errorf("%w '%s', expected a codepoint from U+0000 to U+10FFFF", ErrInvalidNumber, text)
+		}
+		return big.NewInt(int64(codepoint)).Bytes(), nil
+	}
+	if len(text) < 3 || text[len(text)-1] != text[0] {
+		return nil, fmt.Errorf("%w '%s', expected text between matching quotes", ErrInvalidNumber, text)
+	}
+	bytes, err := unescapeC(text[1:len(text)-1], text[0])
+	if err != nil {
+		return nil, fmt.Errorf("%w '%s': %v", ErrInvalidNumber, text, err)
+	}
+	if text[0] == '"' {
+		return bytes, nil
+	}
+
+	// One character, written as it is or as a \u or \U escape, gives its codepoint
+	inner := text[1 : len(text)-1]
+	character, size := utf8.DecodeRuneInString(inner)
+	escape := len(inner) > 2 && inner[0] == '\\' && len(inner) == map[byte]int{'u': 6, 'U': 10}[inner[1]]
+	if (size == len(inner) && character != utf8.RuneError) || escape {
+		codepoint, _ := utf8.DecodeRune(bytes)
+		return big.NewInt(int64(codepoint)).Bytes(), nil
+	}
+	for _, c := range inner {
+		if c > 0x7f {
+			return nil, fmt.Errorf("%w '%s', a character literal holds one character, use a string literal for the UTF-8 bytes of text", ErrInvalidNumber, text)
+		}
+	}
+	return bytes, nil
+}
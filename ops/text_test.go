@@ -0,0 +1,80 @@
+package ops
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestBytesToASCII(t *testing.T) {
+	input := []byte{'J', 0x00, '~', 0x7f, 0xa0, 0xe9, 0xff}
+	if have := BytesToASCII(input, false); have != "J.~...." {
+		t.Errorf("Want J.~...., have %s", have)
+	}
+	if have := BytesToASCII(input, true); have != "J.~.\u00a0éÿ" {
+		t.Errorf("Want J.~.\u00a0éÿ, have %q", have)
+	}
+}
+
+func TestBytesToCString(t *testing.T) {
+	var vector = []struct {
+		input []byte
+		want  string
+	}{
+		{[]byte{}, `""`},
+		{[]byte("abc"), `"abc"`},
+		{[]byte("a\"b\\c\n\t"), `"a\"b\\c\n\t"`},
+		{[]byte{0x00, 'A', 0x01, 'g', 0xff}, `"\x00""A\x01g\xff"`},
+		{[]byte("it's"), `"it's"`},
+	}
+	for _, tt := range vector {
+		if have := BytesToCString(tt.input); have != tt.want {
+			t.Errorf("%v: want %s, have %s", tt.input, tt.want, have)
+		}
+	}
+}
+
+func TestTextToBytes(t *testing.T) {
+	var vector = []struct {
+		input string
+		want  []byte
+	}{
+		{"'A'", []byte{0x41}},
+		{"'AB'", []byte{0x41, 0x42}},
+		{"'é'", []byte{0xe9}},
+		{"'😀'", []byte{0x01, 0xf6, 0x00}},
+		{`'\u00e9'`, []byte{0xe9}},
+		{`'\xc3\xa9'`, []byte{0xc3, 0xa9}},
+		{`'\U0001F600'`, []byte{0x01, 0xf6, 0x00}},
+		{`'\xff'`, []byte{0xff}},
+		{`'\xff\xfe'`, []byte{0xff, 0xfe}},
+		{`"abc"`, []byte("abc")},
+		{`"it's"`, []byte("it's")},
+		{`'"'`, []byte{'"'}},
+		{`'\''`, []byte{'\''}},
+		{`"\0\n\r\t\\\""`, []byte{0, '\n', '\r', '\t', '\\', '"'}},
+		{`"\101\7\0012"`, []byte{'A', 7, 1, '2'}},
+		{`"\x41\x4aZ"`, []byte{'A', 0x4a, 'Z'}},
+		{`"\u00e9"`, []byte("é")},
+		{`"\U0001F600"`, []byte("😀")},
+		{`"é"`, []byte("é")},
+		{"U+41", []byte{0x41}},
+		{"U+1F600", []byte{0x01, 0xf6, 0x00}},
+		{"u+10ffff", []byte{0x10, 0xff, 0xff}},
+		{"U+0", []byte{}},
+	}
+	for _, tt := range vector {
+		have, err := TextToBytes(tt.input)
+		if err != nil || !bytes.Equal(have, tt.want) {
+			t.Errorf("%s: want %v, have %v (%v)", tt.input, tt.want, have, err)
+		}
+		if !IsTextLiteral(tt.input) {
+			t.Errorf("%s: want a text literal", tt.input)
+		}
+	}
+	for _, input := range []string{"''", `"`, `"abc'`, `'a'b'`, `"\"`, `"\q"`, `"\400"`, `"\x100"`, `"\x4a4"`, `"\x"`, `"\u12"`, `"\ud800"`, "'éa'", "U+", "U+110000", "U+xyz"} {
+		if _, err := TextToBytes(input); !errors.Is(err, ErrInvalidNumber) {
+			t.Errorf("Want ErrInvalidNumber for %s, have %v", input, err)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package ops
+
+import (
+	"encoding/binary"
+)
+
+// Number of bytes in the machine words that the arithmetic and bitwise operations work on
+const WORD_BYTES = 8
+
+// Returns the big-endian number in a, which must be at most 8 bytes long, as a uint64
+func loadUint64(a []byte) uint64 {
+	if len(a) == WORD_BYTES {
+		return binary.BigEndian.Uint64(a)
+	}
+	x := uint64(0)
+	for _, b := range a {
+		x = x<<8 | uint64(b)
+	}
+	return x
+}
+
+// Returns the k-th 64-bit word of the big-endian number, counting from the least significant word.
+// Words beyond either end of the number are zero
+func loadWord(a []byte, k int) uint64 {
+	end := len(a) - WORD_BYTES*k
+	if k < 0 || end <= 0 {
+		return 0
+	}
+	return loadUint64(a[Intmax(end-WORD_BYTES, 0):end])
+}
+
+// Returns the number of 64-bit words needed to hold nBytes bytes
+func nWords(nBytes int) int {
+	return (nBytes + WORD_BYTES - 1) / WORD_BYTES
+}
+
+// Writes the low len(dst) bytes of x into dst, big-endian. dst must be at most 8 bytes long
+func storeUint64(dst []byte, x uint64) {
+	if len(dst) == WORD_BYTES {
+		binary.BigEndian.PutUint64(dst, x)
+		return
+	}
+	for i := len(dst) - 1; i >= 0; i-- {
+		dst[i] = byte(x)
+		x >>= 8
+	}
+}
+
+// Writes x as the k-th 64-bit word of the big-endian number in dst, counting from the least significant word.
+// The bits of the most significant word that do not fit in dst are dropped
+func storeWord(dst []byte, k int, x uint64) {
+	end := len(dst) - WORD_BYTES*k
+	if end <= 0 {
+		return
+	}
+	storeUint64(dst[Intmax(end-WORD_BYTES, 0):end], x)
+}
+
+// Returns OP(a, b), applied one 64-bit word at a time. The result has the length of the longer input
+func WordOp(a, b []byte, wordfunc func(ai, bi uint64) uint64) []byte {
+	c := make([]byte, Intmax(len(a), len(b)))
+	for k := 0; k < nWords(len(c)); k++ {
+		storeWord(c, k, wordfunc(loadWord(a, k), loadWord(b, k)))
+	}
+	return c
+}
@@ -0,0 +1,54 @@
+package ops
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+)
+
+// Returns the low nBytes bytes of the number, big-endian
+func bigToBytes(x *big.Int, nBytes int) []byte {
+	mask := big.NewInt(0).Lsh(big.NewInt(1), uint(8*nBytes))
+	x = big.NewInt(0).Mod(x, mask)
+	return x.FillBytes(make([]byte, nBytes))
+}
+
+func TestLoadStoreWord(t *testing.T) {
+	a := []byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a}
+	if have := loadWord(a, 0); have != 0x030405060708090a {
+		t.Errorf("Want word 0 to be 0x030405060708090a, have %#x", have)
+	}
+	if have := loadWord(a, 1); have != 0x0102 {
+		t.Errorf("Want word 1 to be 0x0102, have %#x", have)
+	}
+	if have := loadWord(a, 2) | loadWord(a, -1); have != 0 {
+		t.Errorf("Want words past the ends to be 0, have %#x", have)
+	}
+
+	stored := make([]byte, len(a))
+	storeWord(stored, 0, loadWord(a, 0))
+	storeWord(stored, 1, 0xffff0102)
+	storeWord(stored, 2, 0xff)
+	if !bytes.Equal(stored, a) {
+		t.Errorf("Want %v, have %v", a, stored)
+	}
+}
+
+func TestWordOpsMatchBig(t *testing.T) {
+	// Property: the word-based operations agree with math/big on both sides of the word boundaries
+	check(t, func(a, b []byte, shift uint8) bool {
+		n := Intmax(len(a), len(b))
+		x, y := big.NewInt(0).SetBytes(a), big.NewInt(0).SetBytes(b)
+		sum := big.NewInt(0).Add(x, y)
+		if !bytes.Equal(Add(a, b), bigToBytes(sum, Intmax(n, len(sum.Bytes())))) {
+			return false
+		}
+		if !bytes.Equal(Subtract(a, b), bigToBytes(big.NewInt(0).Sub(x, y), n)) {
+			return false
+		}
+		if !bytes.Equal(ShiftLeft(a, []byte{shift}), bigToBytes(big.NewInt(0).Rsh(x, uint(shift)), len(a))) {
+			return false
+		}
+		return bytes.Equal(ShiftRight(a, []byte{shift}), bigToBytes(big.NewInt(0).Lsh(x, uint(shift)), len(a)))
+	})
+}
@@ -0,0 +1,106 @@
+package register
+
+import (
+	"fmt"
+	"math/big"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// A named range of bits in a register
+type Field struct {
+	Name string
+
+	// Index of the most significant bit in the field
+	Msb uint
+
+	// Index of the least significant bit in the field
+	Lsb uint
+}
+
+// The fields of a register, in the order they were defined
+type Definition []Field
+
+var fieldPattern = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*)\[(\d+)(?::(\d+))?\]$`)
+
+// Reads a register definition from a file, or parses the argument itself if it is not the path to a file
+func Load(specOrPath string) (Definition, error) {
+	if contents, err := os.ReadFile(specOrPath); err == nil {
+		return Parse(string(contents))
+	}
+	return Parse(specOrPath)
+}
+
+// Parses a register definition such as "EN[0],MODE[3:1],DIV[15:8]". Fields may also be separated by newlines,
+// and anything after a # is a comment
+func Parse(spec string) (Definition, error) {
+	definition := Definition{}
+	for _, line := range strings.Split(spec, "\n") {
+		if comment := strings.Index(line, "#"); comment >= 0 {
+			line = line[:comment]
+		}
+		for _, part := range strings.Split(line, ",") {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+			match := fieldPattern.FindStringSubmatch(part)
+			if match == nil {
+				return nil, fmt.Errorf("invalid register field '%s', expected e.g. NAME[7:4] or NAME[0]", part)
+			}
+			msb, err := strconv.ParseUint(match[2], 10, 16)
+			if err != nil {
+				return nil, fmt.Errorf("invalid register field '%s', bit %s is out of range", part, match[2])
+			}
+			lsb := msb
+			if match[3] != "" {
+				lsb, err = strconv.ParseUint(match[3], 10, 16)
+				if err != nil {
+					return nil, fmt.Errorf("invalid register field '%s', bit %s is out of range", part, match[3])
+				}
+			}
+			if lsb > msb {
+				return nil, fmt.Errorf("invalid register field '%s', the most significant bit must come first", part)
+			}
+			definition = append(definition, Field{match[1], uint(msb), uint(lsb)})
+		}
+	}
+	if len(definition) == 0 {
+		return nil, fmt.Errorf("register definition has no fields")
+	}
+	return definition, nil
+}
+
+// Returns an error for the first field that does not fit in a register with the given number of bits
+func (d Definition) CheckWidth(bits uint) error {
+	for _, field := range d {
+		if field.Msb >= bits {
+			return fmt.Errorf("register field '%s' does not fit in %d bits", field, bits)
+		}
+	}
+	return nil
+}
+
+// Returns the value of the field in the big-endian register value
+func (f Field) Extract(value []byte) []byte {
+	mask := new(big.Int).Lsh(big.NewInt(1), f.Width())
+	mask.Sub(mask, big.NewInt(1))
+	extracted := new(big.Int).SetBytes(value)
+	extracted.Rsh(extracted, f.Lsb)
+	return extracted.And(extracted, mask).Bytes()
+}
+
+// Returns the field as it is written in a register definition
+func (f Field) String() string {
+	if f.Msb == f.Lsb {
+		return fmt.Sprintf("%s[%d]", f.Name, f.Msb)
+	}
+	return fmt.Sprintf("%s[%d:%d]", f.Name, f.Msb, f.Lsb)
+}
+
+// Returns the number of bits in the field
+func (f Field) Width() uint {
+	return f.Msb - f.Lsb + 1
+}
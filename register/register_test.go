@@ -0,0 +1,56 @@
+package register
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestCheckWidth(t *testing.T) {
+	definition := Definition{{"EN", 0, 0}, {"DIV", 15, 8}}
+	if err := definition.CheckWidth(16); err != nil {
+		t.Errorf("Want no error for 16 bits, have %v\n", err)
+	}
+	if err := definition.CheckWidth(8); err == nil {
+		t.Errorf("Want error for 8 bits\n")
+	}
+}
+
+func TestExtract(t *testing.T) {
+	var vector = []struct {
+		field Field
+		value []byte
+		want  []byte
+	}{
+		{Field{"EN", 0, 0}, []byte{0x18, 0x77}, []byte{1}},
+		{Field{"EN", 3, 3}, []byte{0x18, 0x77}, []byte{}},
+		{Field{"MODE", 6, 4}, []byte{0x18, 0x77}, []byte{7}},
+		{Field{"DIV", 15, 8}, []byte{0x18, 0x77}, []byte{0x18}},
+		{Field{"WIDE", 11, 4}, []byte{0x18, 0x77}, []byte{0x87}},
+		{Field{"OUTSIDE", 23, 16}, []byte{0x18, 0x77}, []byte{}},
+	}
+	for _, tt := range vector {
+		testname := fmt.Sprintf("%v,%v\n", tt.field, tt.value)
+		t.Run(testname, func(t *testing.T) {
+			have := tt.field.Extract(tt.value)
+			if !bytes.Equal(have, tt.want) {
+				t.Errorf("Want %v, have %v\n", tt.want, have)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	have, err := Parse("EN[0], MODE[3:1] # the mode\nDIV[15:8]")
+	want := Definition{{"EN", 0, 0}, {"MODE", 3, 1}, {"DIV", 15, 8}}
+	if err != nil || !reflect.DeepEqual(have, want) {
+		t.Errorf("Want %v, have %v (error %v)\n", want, have, err)
+	}
+
+	for _, spec := range []string{"", "EN", "EN[1:3]", "EN[x]", "1EN[0]", "EN[99999]", "EN[7:99999]"} {
+		if _, err := Parse(spec); err == nil {
+			t.Errorf("Want error for %q\n", spec)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package table
+
+import (
+	"fmt"
+	"github.com/jonathangjertsen/jco-go/jco"
+	"io"
+	"strconv"
+)
+
+// A table which shows each byte of one or more values on its own row, with its index, the bits it holds in the
+// value, its decimal, hexadecimal and binary value and the character it is in ASCII. Bytes are numbered in the order
+// they are laid out in memory: from the most significant byte by default, or from the least significant byte if
+// LittleEndian is set
+type Bytes struct {
+	values       []jco.Value
+	metavars     []string
+	format       Format
+	columns      Selection
+	littleEndian bool
+}
+
+// Returns the byte as a C character literal if it is printable ASCII, and "." otherwise
+func asciiCell(b byte) string {
+	if b < 0x20 || b > 0x7e {
+		return "."
+	}
+	return "'" + string(rune(b)) + "'"
+}
+
+func NewBytes() *Bytes {
+	return &Bytes{}
+}
+
+// Adds a row for each byte of the value
+func (b *Bytes) One(value jco.Value, metavar string) {
+	b.values = append(b.values, value)
+	b.metavars = append(b.metavars, metavar)
+}
+
+// Writes the rendered table
+func (b *Bytes) RenderTo(writer io.Writer) error {
+	_, err := io.WriteString(writer, b.String())
+	return err
+}
+
+func (b *Bytes) SetColumns(columns Selection) {
+	b.columns = columns
+}
+
+func (b *Bytes) SetFormat(format Format) {
+	b.format = format
+}
+
+// Sets whether byte 0 is the least significant byte rather than the most significant
+func (b *Bytes) SetLittleEndian(littleEndian bool) {
+	b.littleEndian = littleEndian
+}
+
+// Returns the rendered table. The formula column names the value on the first row of its bytes, and the selected
+// value columns are shown between the bit range and the ASCII column
+func (b *Bytes) String() string {
+	selected := b.columns.ApplyDefaults(DEFAULT_COLUMNS, DEFAULT_COLUMNS)
+	header := []string{}
+	for _, name := range selected {
+		header = append(header, map[string]string{
+			"formula": "FORMULA",
+			"dec":     "DECIMAL",
+			"hex":     "HEXADECIMAL",
+			"bin":     "BINARY",
+		}[name])
+		if name == "formula" {
+			header = append(header, "|", "BYTE", "BITS")
+		}
+	}
+	header = append(header, "ASCII")
+	if !contains(selected, "formula") {
+		header = append([]string{"BYTE", "BITS"}, header...)
+	}
+	grid := [][]string{header}
+
+	for v, value := range b.values {
+		bytes := value.Bytes()
+		for index := range bytes {
+			// Bytes are stored from the most significant, so little-endian indices count from the other end
+			position := index
+			if b.littleEndian {
+				position = len(bytes) - 1 - index
+			}
+			byteValue, _ := jco.FromUint64(uint64(bytes[position]), 8)
+			highBit := 8*(len(bytes)-position) - 1
+			cells := map[string]string{
+				"dec": byteValue.DecGrouped(b.format.GroupDec, b.format.Separator),
+				"hex": byteValue.HexGrouped(b.format.GroupHex, b.format.Separator),
+				"bin": byteValue.BinGrouped(b.format.GroupBin, b.format.Separator),
+			}
+			if index == 0 {
+				cells["formula"] = b.metavars[v]
+			}
+			location := []string{strconv.Itoa(index), fmt.Sprintf("[%d:%d]", highBit, highBit-7)}
+			row := []string{}
+			for _, name := range selected {
+				row = append(row, cells[name])
+				if name == "formula" {
+					row = append(row, "|")
+					row = append(row, location...)
+				}
+			}
+			if !contains(selected, "formula") {
+				row = append(location, row...)
+			}
+			grid = append(grid, append(row, asciiCell(bytes[position])))
+		}
+	}
+	return RenderGrid(grid, 1, b.format.Color)
+}
@@ -0,0 +1,39 @@
+package table
+
+import (
+	"github.com/jonathangjertsen/jco-go/jco"
+	"strings"
+	"testing"
+)
+
+func TestBytes(t *testing.T) {
+	value, _ := jco.Parse("0x41000a7e", 32)
+	for _, tt := range []struct {
+		littleEndian bool
+		want         []string
+	}{
+		{false, []string{
+			"      FORMULA   |   BYTE      BITS   HEXADECIMAL   ASCII",
+			"   0x41000a7e   |      0   [31:24]          0x41     'A'",
+			"                |      1   [23:16]          0x00       .",
+			"                |      2    [15:8]          0x0a       .",
+			"                |      3     [7:0]          0x7e     '~'",
+		}},
+		{true, []string{
+			"      FORMULA   |   BYTE      BITS   HEXADECIMAL   ASCII",
+			"   0x41000a7e   |      0     [7:0]          0x7e     '~'",
+			"                |      1    [15:8]          0x0a       .",
+			"                |      2   [23:16]          0x00       .",
+			"                |      3   [31:24]          0x41     'A'",
+		}},
+	} {
+		table := NewBytes()
+		table.SetColumns(ParseSelection("formula,hex"))
+		table.SetLittleEndian(tt.littleEndian)
+		table.One(value, "0x41000a7e")
+		have := strings.Split(strings.TrimSuffix(table.String(), "\n"), "\n")
+		if strings.Join(have, "\n") != strings.Join(tt.want, "\n") {
+			t.Errorf("Want\n%s\nhave\n%s", strings.Join(tt.want, "\n"), strings.Join(have, "\n"))
+		}
+	}
+}
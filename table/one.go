@@ -2,18 +2,20 @@ package table
 
 import (
 	"fmt"
+	"github.com/jonathangjertsen/jco-go/jco"
 	"github.com/jonathangjertsen/jco-go/ops"
 )
 
-func (t *Table) One(a []byte, metavar string) {
-	t.Add(fmt.Sprintf("%s ", metavar), a)
-	t.Add(fmt.Sprintf("~%s ", metavar), ops.Not(a))
-	t.Add(fmt.Sprintf("twos_complement(%s)", metavar), ops.TwosComplement(a))
-	t.Add(fmt.Sprintf("popcount(%s)", metavar), ops.Popcount(a))
-	t.Add(fmt.Sprintf("clz(%s)", metavar), ops.Clz(a))
-	t.Add(fmt.Sprintf("nbits(%s)", metavar), ops.Nbits(a))
-	t.Add(fmt.Sprintf("reverse_bitstring(%s)", metavar), ops.BitstringReverse(a))
-	t.Add(fmt.Sprintf("reverse_bitorder(%s)", metavar), ops.BitReverse(a))
-	t.Add(fmt.Sprintf("reverse_byteorder(%s)", metavar), ops.ByteReverse(a))
-	t.Add(fmt.Sprintf("reverse_nibbleorder(%s)", metavar), ops.NibbleSwap(a))
+func (t *Table) One(a jco.Value, metavar string) {
+	for _, name := range t.operations.ApplyDefaults(ops.OperationNames(1), ops.DefaultOperationNames(1)) {
+		op, _ := ops.LookupOperation(name, 1)
+		result, truncated, _ := a.Apply(name)
+		t.Add(Row{name, []string{metavar}, fmt.Sprintf(op.Formula, metavar), result, truncated, invalid(a, name)})
+	}
+}
+
+// Adds the rows for one number like One, after converting it to the type. If that changes its type, a row
+// showing the conversion comes first
+func (t *Table) OneAs(a jco.Value, metavar string, to jco.Type) {
+	t.One(t.convert(a, metavar, to), metavar)
 }
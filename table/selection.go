@@ -0,0 +1,81 @@
+package table
+
+import (
+	"fmt"
+	"strings"
+)
+
+// A user's choice of which named items to show, as given by e.g. "popcount,clz" or "-bin"
+type Selection struct {
+	// Items to show, in order. If empty, all items are shown in their default order
+	Include []string
+
+	// Items to hide
+	Exclude []string
+}
+
+// Returns whether the name is in names
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Parses a comma-separated list of names, where names prefixed with '-' are hidden
+func ParseSelection(spec string) Selection {
+	selection := Selection{}
+	for _, name := range strings.Split(spec, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if strings.HasPrefix(name, "-") {
+			selection.Exclude = append(selection.Exclude, name[1:])
+		} else {
+			selection.Include = append(selection.Include, name)
+		}
+	}
+	return selection
+}
+
+// Returns whether the name is hidden by the selection
+func (s Selection) excludes(name string) bool {
+	return contains(s.Exclude, name)
+}
+
+// Returns the selected subset of names, in the order they should be shown
+func (s Selection) Apply(names []string) []string {
+	selected := []string{}
+	candidates := names
+	if len(s.Include) > 0 {
+		candidates = s.Include
+	}
+	for _, name := range candidates {
+		if s.excludes(name) || !contains(names, name) {
+			continue
+		}
+		selected = append(selected, name)
+	}
+	return selected
+}
+
+// Returns the selected subset of names like Apply, except that only the defaults are shown if nothing is included
+func (s Selection) ApplyDefaults(names []string, defaults []string) []string {
+	if len(s.Include) == 0 {
+		s.Include = defaults
+	}
+	return s.Apply(names)
+}
+
+// Returns an error if the selection mentions a name that is not in valid
+func (s Selection) Validate(valid []string) error {
+	for _, name := range append(append([]string{}, s.Include...), s.Exclude...) {
+		if !contains(valid, name) {
+			return fmt.Errorf("unknown name '%s', expected one of %s", name, strings.Join(valid, ","))
+		}
+	}
+	return nil
+}
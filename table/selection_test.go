@@ -0,0 +1,59 @@
+package table
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestSelectionApply(t *testing.T) {
+	names := []string{"value", "not", "popcount", "clz"}
+	var vector = []struct {
+		spec string
+		want []string
+	}{
+		{"", []string{"value", "not", "popcount", "clz"}},
+		{"clz,value", []string{"clz", "value"}},
+		{"-not,-clz", []string{"value", "popcount"}},
+		{"clz,popcount,-clz", []string{"popcount"}},
+		{" popcount , ", []string{"popcount"}},
+		{"add", []string{}},
+	}
+	for _, tt := range vector {
+		testname := fmt.Sprintf("%v\n", tt.spec)
+		t.Run(testname, func(t *testing.T) {
+			have := ParseSelection(tt.spec).Apply(names)
+			if !reflect.DeepEqual(have, tt.want) {
+				t.Errorf("Want %v, have %v\n", tt.want, have)
+			}
+		})
+	}
+}
+
+func TestSelectionApplyDefaults(t *testing.T) {
+	names := []string{"dec", "hex", "bin", "base58"}
+	defaults := []string{"dec", "hex", "bin"}
+	var vector = []struct {
+		spec string
+		want []string
+	}{
+		{"", []string{"dec", "hex", "bin"}},
+		{"-hex", []string{"dec", "bin"}},
+		{"base58,dec", []string{"base58", "dec"}},
+	}
+	for _, tt := range vector {
+		if have := ParseSelection(tt.spec).ApplyDefaults(names, defaults); !reflect.DeepEqual(have, tt.want) {
+			t.Errorf("%s: want %v, have %v", tt.spec, tt.want, have)
+		}
+	}
+}
+
+func TestSelectionValidate(t *testing.T) {
+	valid := []string{"dec", "hex"}
+	if err := ParseSelection("hex,-dec").Validate(valid); err != nil {
+		t.Errorf("Want no error, have %v\n", err)
+	}
+	if err := ParseSelection("hex,-bin").Validate(valid); err == nil {
+		t.Errorf("Want error for unknown name\n")
+	}
+}
@@ -2,70 +2,351 @@ package table
 
 import (
 	"fmt"
+	"github.com/jonathangjertsen/jco-go/jco"
 	"github.com/jonathangjertsen/jco-go/ops"
+	"github.com/jonathangjertsen/jco-go/register"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
 )
 
 const (
-	N_COLUMNS = 5
-	PADDING   = 3
+	// The columns up to COLUMN_ENCODINGS, and one for each of ops.ENCODINGS
+	N_COLUMNS = 11
+
+	PADDING = 3
+)
+
+// ANSI escape codes used when Format.Color is set
+const (
+	COLOR_HEADER    = "\x1b[1m"
+	COLOR_TRUNCATED = "\x1b[33m"
+	COLOR_RESET     = "\x1b[0m"
 )
 
+const (
+	COLUMN_FORMULA = iota
+	COLUMN_SEPARATOR
+	COLUMN_DEC
+	COLUMN_HEX
+	COLUMN_BIN
+	COLUMN_BASE
+
+	// One column for each of ops.ENCODINGS, in the same order
+	COLUMN_ENCODINGS
+)
+
+// Maps the names of the columns that can be selected to their index
+var COLUMN_NAMES = map[string]int{
+	"formula": COLUMN_FORMULA,
+	"dec":     COLUMN_DEC,
+	"hex":     COLUMN_HEX,
+	"bin":     COLUMN_BIN,
+	"base":    COLUMN_BASE,
+}
+
+// Columns shown when none are selected. The base column is added when Format.OutBase is set
+var DEFAULT_COLUMNS = []string{"formula", "dec", "hex", "bin"}
+
+type Format struct {
+	GroupBin  uint
+	GroupHex  uint
+	GroupDec  uint
+	Separator string
+	Ruler     bool
+
+	// Base from 2 to 64 of the base column, or 0 to leave the column out unless it is selected
+	OutBase uint
+
+	// Digits of the base column, where base N uses the first N of them, or empty for ops.RADIX_DIGITS
+	Alphabet string
+
+	// Whether to highlight the header and truncated values with ANSI escape codes
+	Color bool
+}
+
+// The result of one operation in the table
+type Row struct {
+	// Name of the operation, e.g. "add", or the register field for rows added by Fields
+	Operation string
+
+	// Operands as they were written, e.g. ["0x1877", "5"], in the order they were given to the operation
+	Operands []string
+
+	// Formula shown in the first column, e.g. "0x1877  + 5"
+	Formula string
+
+	// Result of the operation, with the width of the table
+	Value jco.Value
+
+	// Whether the result had to be truncated to fit the width
+	Truncated bool
+
+	// Why the result is meaningless, e.g. because an operand is not valid BCD, or empty
+	Invalid string
+}
+
 type Table struct {
-	table [][N_COLUMNS]string
-	bytes uint
+	rows       []Row
+	bytes      uint
+	format     Format
+	operations Selection
+	columns    Selection
+}
+
+// Wraps the text of a padded cell in the color, leaving the padding alone so that the columns stay aligned
+func colorize(cell string, color string) string {
+	text := strings.TrimLeft(cell, " ")
+	return cell[:len(cell)-len(text)] + color + text + COLOR_RESET
+}
+
+// Adds the columns for the named encodings
+func init() {
+	for i, name := range ops.EncodingNames() {
+		COLUMN_NAMES[name] = COLUMN_ENCODINGS + i
+	}
+}
+
+// Returns why the result of the named operation is meaningless for the operands, or "" if it is not
+func invalid(a jco.Value, name string, others ...jco.Value) string {
+	if err := a.Check(name, others...); err != nil {
+		return err.Error()
+	}
+	return ""
+}
+
+// Returns the names of the columns that can be selected: the default columns, followed by the base column and one
+// column for each of the named encodings
+func ColumnNames() []string {
+	return append(append(append([]string{}, DEFAULT_COLUMNS...), "base"), ops.EncodingNames()...)
 }
 
 func NewTable(bits uint) *Table {
 	return &Table{
-		table: [][N_COLUMNS]string{
-			{"FORMULA", "|", "DECIMAL", "HEXADECIMAL", "BINARY"},
-		},
 		bytes: (bits + 7) / 8,
 	}
 }
 
-func (t *Table) Add(name string, value []byte) {
-	if t.bytes > uint(len(value)) {
-		padding := t.bytes - uint(len(value))
-		value = ops.PrependZeros(value, uint(padding))
+// Returns the grid with the cells in each column right-aligned, counting characters rather than bytes. The first
+// nHeaders rows are highlighted as headers if color is set, and so are cells in the other rows which are marked as
+// truncated or invalid
+func RenderGrid(grid [][]string, nHeaders int, color bool) string {
+	widths := []int{}
+	for _, row := range grid {
+		for c, text := range row {
+			if c >= len(widths) {
+				widths = append(widths, 0)
+			}
+			widths[c] = ops.Intmax(widths[c], utf8.RuneCountInString(text))
+		}
 	}
-	valueTruncated := ops.Truncate(value, t.bytes)
 
-	dec := ops.BytesToDec(valueTruncated, t.bytes)
-	hex := ops.BytesToHex(valueTruncated, t.bytes)
-	bin := ops.BytesToBin(valueTruncated, t.bytes)
+	// Every cell is right-aligned with spaces in front of it, and empty cells at the end of a row are left out
+	var builder strings.Builder
+	for r, row := range grid {
+		for len(row) > 0 && row[len(row)-1] == "" {
+			row = row[:len(row)-1]
+		}
+		for c, text := range row {
+			padding := widths[c] + PADDING - utf8.RuneCountInString(text)
+			if color && text != "" && (r < nHeaders || strings.HasPrefix(text, "*") || strings.HasPrefix(text, "!")) {
+				highlight := COLOR_TRUNCATED
+				if r < nHeaders {
+					highlight = COLOR_HEADER
+				}
+				builder.WriteString(colorize(strings.Repeat(" ", padding)+text, highlight))
+				continue
+			}
+			for i := 0; i < padding; i++ {
+				builder.WriteByte(' ')
+			}
+			builder.WriteString(text)
+		}
+		builder.WriteString("\n")
+	}
+	return builder.String()
+}
 
-	if !ops.Equivalent(value, valueTruncated) {
-		dec = "*" + dec
-		hex = "*" + hex
-		bin = "*" + bin
+// Returns the text of the given columns in the row, formatted according to the table's format. The formula and
+// separator are always filled in
+func (t *Table) cells(row Row, columns []int) [N_COLUMNS]string {
+	cells := [N_COLUMNS]string{row.Formula, "|"}
+	for _, c := range columns {
+		switch {
+		case c == COLUMN_DEC:
+			cells[c] = row.Value.DecGrouped(t.format.GroupDec, t.format.Separator)
+		case c == COLUMN_HEX:
+			cells[c] = row.Value.HexGrouped(t.format.GroupHex, t.format.Separator)
+		case c == COLUMN_BIN:
+			cells[c] = row.Value.BinGrouped(t.format.GroupBin, t.format.Separator)
+		case c == COLUMN_BASE:
+			if uint(len(t.format.Alphabet)) >= t.outBase() {
+				cells[c] = ops.BytesToAlphabet(row.Value.Bytes(), t.format.Alphabet[:t.outBase()])
+			} else {
+				cells[c] = ops.BytesToRadix(row.Value.Bytes(), t.outBase())
+			}
+		case c >= COLUMN_ENCODINGS:
+			cells[c] = ops.ENCODINGS[c-COLUMN_ENCODINGS].Encode(row.Value.Bytes())
+		default:
+			continue
+		}
+		if row.Invalid != "" {
+			cells[c] = "!" + cells[c]
+		}
+		if row.Truncated {
+			cells[c] = "*" + cells[c]
+		}
 	}
+	return cells
+}
 
-	t.table = append(t.table, [N_COLUMNS]string{
-		name,
-		"|",
-		dec,
-		hex,
-		bin,
-	})
+// Adds a row showing the value converted to the type, unless it already has that type, and returns the converted
+// value. The row is marked as truncated if the conversion changed the number, e.g. by cutting off bits or by
+// turning a negative number into a large unsigned one
+func (t *Table) convert(value jco.Value, metavar string, to jco.Type) jco.Value {
+	if value.Type() == to {
+		return value
+	}
+	converted, changed, _ := value.Convert(to)
+	t.Add(Row{"convert", []string{metavar}, fmt.Sprintf("(%s)%s", to, metavar), converted, changed, ""})
+	return converted
 }
 
-func (t *Table) Render() {
-	nRows := len(t.table)
-	widths := [N_COLUMNS]int{}
-	for c := 0; c < N_COLUMNS; c++ {
-		for r := 0; r < nRows; r++ {
-			widths[c] = ops.Intmax(widths[c], len(t.table[r][c]))
+// Returns the header row
+func (t *Table) header() [N_COLUMNS]string {
+	header := [N_COLUMNS]string{"FORMULA", "|", "DECIMAL", "HEXADECIMAL", "BINARY", fmt.Sprintf("BASE %d", t.outBase())}
+	for i, encoding := range ops.ENCODINGS {
+		header[COLUMN_ENCODINGS+i] = strings.ToUpper(encoding.Name)
+	}
+	return header
+}
+
+// Returns a line for each row which is marked as invalid, saying why, with the label after the formula
+func (t *Table) notes(label string) string {
+	var builder strings.Builder
+	for _, row := range t.rows {
+		if row.Invalid != "" {
+			fmt.Fprintf(&builder, "  ! %s%s: %s\n", strings.TrimSpace(row.Formula), label, row.Invalid)
 		}
 	}
-	formatStrings := [N_COLUMNS]string{}
-	for c := 0; c < N_COLUMNS; c++ {
-		formatStrings[c] = fmt.Sprintf("%%%ds", widths[c]+PADDING)
+	return builder.String()
+}
+
+// Returns the base of the base column, which is 10 if Format.OutBase is not set
+func (t *Table) outBase() uint {
+	if t.format.OutBase == 0 {
+		return 10
+	}
+	return t.format.OutBase
+}
+
+// Returns the row which labels the bit indices above the binary column
+func (t *Table) ruler() [N_COLUMNS]string {
+	ruler := [N_COLUMNS]string{"", "|"}
+	ruler[COLUMN_BIN] = ops.BitRuler(t.bytes, t.format.GroupBin, t.format.Separator)
+	return ruler
+}
+
+// Returns the indices of the columns to render, with the separator following the formula if anything comes after it
+func (t *Table) selectedColumns() []int {
+	columns := []int{}
+	selected := t.Columns()
+	for i, name := range selected {
+		columns = append(columns, COLUMN_NAMES[name])
+		if name == "formula" && i+1 < len(selected) {
+			columns = append(columns, COLUMN_SEPARATOR)
+		}
+	}
+	return columns
+}
+
+// Adds a row to the table
+func (t *Table) Add(row Row) {
+	t.rows = append(t.rows, row)
+}
+
+// Returns the cells of every row below the header, with one cell for each of the selected Columns
+func (t *Table) Cells() [][]string {
+	cells := [][]string{}
+	columns := t.selectedColumns()
+	for _, row := range t.rows {
+		rowCells := []string{}
+		text := t.cells(row, columns)
+		for _, c := range columns {
+			if c != COLUMN_SEPARATOR {
+				rowCells = append(rowCells, text[c])
+			}
+		}
+		cells = append(cells, rowCells)
+	}
+	return cells
+}
+
+// Returns the names of the selected columns, in the order they are rendered
+func (t *Table) Columns() []string {
+	defaults := DEFAULT_COLUMNS
+	if t.format.OutBase != 0 {
+		defaults = append(append([]string{}, DEFAULT_COLUMNS...), "base")
+	}
+	return t.columns.ApplyDefaults(ColumnNames(), defaults)
+}
+
+// Adds a row for each field of the register, showing the value of that field in the given register value
+func (t *Table) Fields(value jco.Value, definition register.Definition) {
+	for _, field := range definition {
+		fieldValue, _ := jco.New(field.Extract(value.Bytes()), value.Bits())
+		t.Add(Row{Operation: field.String(), Formula: field.String(), Value: fieldValue})
 	}
-	for r := 0; r < nRows; r++ {
-		for c := 0; c < N_COLUMNS; c++ {
-			fmt.Printf(formatStrings[c], t.table[r][c])
+}
+
+// Prints the rendered table to stdout
+func (t *Table) Render() {
+	t.RenderTo(os.Stdout)
+}
+
+// Writes the rendered table
+func (t *Table) RenderTo(w io.Writer) error {
+	_, err := io.WriteString(w, t.String())
+	return err
+}
+
+// Returns a copy of the rows, in the order they were added. Rows hidden by SetOperations are never added
+func (t *Table) Rows() []Row {
+	return append([]Row{}, t.rows...)
+}
+
+func (t *Table) SetColumns(columns Selection) {
+	t.columns = columns
+}
+
+func (t *Table) SetFormat(format Format) {
+	t.format = format
+}
+
+func (t *Table) SetOperations(operations Selection) {
+	t.operations = operations
+}
+
+// Returns the rendered table, followed by a note for each row which is marked as invalid
+func (t *Table) String() string {
+	columns := t.selectedColumns()
+	rows := make([][N_COLUMNS]string, 0, len(t.rows)+2)
+	rows = append(rows, t.header())
+	for _, row := range t.rows {
+		rows = append(rows, t.cells(row, columns))
+	}
+	if t.format.Ruler {
+		rows = append([][N_COLUMNS]string{rows[0], t.ruler()}, rows[1:]...)
+	}
+
+	grid := make([][]string, 0, len(rows))
+	for _, row := range rows {
+		cells := make([]string, 0, len(columns))
+		for _, c := range columns {
+			cells = append(cells, row[c])
 		}
-		fmt.Println("")
+		grid = append(grid, cells)
 	}
+	return RenderGrid(grid, 1, t.format.Color) + t.notes("")
 }
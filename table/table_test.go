@@ -0,0 +1,130 @@
+package table
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/jonathangjertsen/jco-go/jco"
+	"github.com/jonathangjertsen/jco-go/ops"
+	"reflect"
+	"testing"
+)
+
+// Keeps the rendered tables in the benchmarks from being optimized away
+var benchmarkOutput string
+
+func BenchmarkTwo(b *testing.B) {
+	for _, bits := range []uint{32, 256} {
+		x, _ := jco.Parse("0x1877", bits)
+		y, _ := jco.Parse("13", bits)
+		b.Run(fmt.Sprintf("%dbit", bits), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				table := NewTable(bits)
+				table.Two(x, y, "x", "y")
+				benchmarkOutput = table.String()
+			}
+		})
+	}
+}
+
+func TestEncodingColumns(t *testing.T) {
+	value, _ := jco.Parse("0x1877", 32)
+	table := NewTable(32)
+	table.SetOperations(ParseSelection("value"))
+	table.SetFormat(Format{OutBase: 36})
+	table.One(value, "x")
+	if have := table.Columns(); !reflect.DeepEqual(have, []string{"formula", "dec", "hex", "bin", "base"}) {
+		t.Errorf("Want the base column after the defaults, have %v", have)
+	}
+
+	table.SetColumns(ParseSelection("base,base32,crockford32,base58,base64,base64url"))
+	want := [][]string{{"4tz", "AAABQ5Y=", "63Q", "112rz", "AAAYdw==", "AAAYdw"}}
+	if have := table.Cells(); !reflect.DeepEqual(have, want) {
+		t.Errorf("Want %v, have %v", want, have)
+	}
+}
+
+func TestInvalidRows(t *testing.T) {
+	value, _ := jco.Parse("0x1a", 8)
+	table := NewTable(8)
+	table.One(value, "x")
+	if len(table.Rows()) != len(ops.DefaultOperationNames(1)) {
+		t.Errorf("Want only the default operations, have %d rows", len(table.Rows()))
+	}
+
+	table = NewTable(8)
+	table.SetOperations(ParseSelection("bcd,from_bcd"))
+	table.SetColumns(ParseSelection("formula,hex"))
+	table.One(value, "x")
+	want := "       FORMULA   |   HEXADECIMAL\n" +
+		"        bcd(x)   |          0x26\n" +
+		"   from_bcd(x)   |         !0x14\n" +
+		"  ! from_bcd(x): 0xa in bits [3:0] is not a decimal digit\n"
+	if have := table.String(); have != want {
+		t.Errorf("Want\n%s\nhave\n%s", want, have)
+	}
+}
+
+func TestRenderTo(t *testing.T) {
+	value, _ := jco.Parse("0x12", 8)
+	table := NewTable(8)
+	table.SetOperations(ParseSelection("value"))
+	table.One(value, "0x12")
+
+	var buffer bytes.Buffer
+	if err := table.RenderTo(&buffer); err != nil {
+		t.Fatal(err)
+	}
+	want := "   FORMULA   |   DECIMAL   HEXADECIMAL       BINARY\n     0x12    |        18          0x12   0b00010010\n"
+	if buffer.String() != want {
+		t.Errorf("Want\n%s\nhave\n%s", want, buffer.String())
+	}
+}
+
+func TestRows(t *testing.T) {
+	a, _ := jco.Parse("0xff", 8)
+	b, _ := jco.Parse("0x01", 8)
+	table := NewTable(8)
+	table.SetOperations(ParseSelection("add,sub"))
+	table.Two(a, b, "A", "B")
+
+	rows := table.Rows()
+	var want = []struct {
+		operation string
+		operands  []string
+		formula   string
+		hex       string
+		truncated bool
+	}{
+		{"add", []string{"A", "B"}, "A  + B", "0x00", true},
+		{"sub", []string{"A", "B"}, "A  - B", "0xfe", false},
+		{"sub", []string{"B", "A"}, "B  - A", "0x02", false},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("Want %d rows, have %d", len(want), len(rows))
+	}
+	for i, row := range rows {
+		w := want[i]
+		if row.Operation != w.operation || !reflect.DeepEqual(row.Operands, w.operands) || row.Formula != w.formula ||
+			row.Value.Hex() != w.hex || row.Truncated != w.truncated {
+			t.Errorf("Row %d: want %+v, have %+v", i, w, row)
+		}
+	}
+}
+
+func TestTwoAs(t *testing.T) {
+	a, _ := jco.Parse("0x12", 8)
+	b, _ := jco.Parse("0xfff0", 16)
+	table := NewTable(32)
+	table.SetOperations(ParseSelection("add"))
+	table.TwoAs(a, b.AsSigned(), "a", "b", jco.Type{Bits: 32, Signed: true})
+
+	want := []string{"(i32)a=18", "(i32)b=-16", "a  + b=2"}
+	have := []string{}
+	for _, row := range table.Rows() {
+		have = append(have, row.Formula+"="+row.Value.Dec())
+	}
+	if !reflect.DeepEqual(have, want) {
+		t.Errorf("Want %v, have %v", want, have)
+	}
+}
@@ -0,0 +1,104 @@
+package table
+
+import (
+	"fmt"
+	"github.com/jonathangjertsen/jco-go/jco"
+	"github.com/jonathangjertsen/jco-go/ops"
+	"io"
+	"math/big"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// A table which shows the bytes of one or more values as text (ASCII, Latin-1 and a C string literal), and the
+// value as a Unicode codepoint with its UTF-8, UTF-16 and UTF-32 encodings
+type Text struct {
+	values   []jco.Value
+	metavars []string
+	format   Format
+}
+
+// Returns the bytes in hex, separated by spaces
+func hexBytes(bytes []byte) string {
+	parts := []string{}
+	for _, b := range bytes {
+		parts = append(parts, fmt.Sprintf("%02x", b))
+	}
+	return strings.Join(parts, " ")
+}
+
+// Returns the rows for the value: the name of each encoding and the text or bytes it gives
+func textRows(value jco.Value) [][2]string {
+	bytes := value.Bytes()
+	rows := [][2]string{
+		{"ASCII", ops.BytesToASCII(bytes, false)},
+		{"Latin-1", ops.BytesToASCII(bytes, true)},
+		{"C string", ops.BytesToCString(bytes)},
+	}
+
+	// Values which are not codepoints only get the reason
+	number := big.NewInt(0).SetBytes(bytes)
+	codepoint := rune(number.Int64())
+	switch {
+	case number.Cmp(big.NewInt(ops.MAX_CODEPOINT)) > 0:
+		return append(rows, [2]string{"Codepoint", "none, above U+10FFFF"})
+	case !utf8.ValidRune(codepoint):
+		return append(rows, [2]string{"Codepoint", "none, a UTF-16 surrogate"})
+	}
+	name := fmt.Sprintf("U+%04X", codepoint)
+	if unicode.IsPrint(codepoint) {
+		name += " " + string(codepoint)
+	}
+	utf16BE := []byte{}
+	utf16LE := []byte{}
+	for _, unit := range utf16.Encode([]rune{codepoint}) {
+		utf16BE = append(utf16BE, byte(unit>>8), byte(unit))
+		utf16LE = append(utf16LE, byte(unit), byte(unit>>8))
+	}
+	utf32BE := []byte{0, byte(codepoint >> 16), byte(codepoint >> 8), byte(codepoint)}
+	return append(rows,
+		[2]string{"Codepoint", name},
+		[2]string{"UTF-8", hexBytes([]byte(string(codepoint)))},
+		[2]string{"UTF-16BE", hexBytes(utf16BE)},
+		[2]string{"UTF-16LE", hexBytes(utf16LE)},
+		[2]string{"UTF-32BE", hexBytes(utf32BE)},
+		[2]string{"UTF-32LE", hexBytes([]byte{utf32BE[3], utf32BE[2], utf32BE[1], 0})},
+	)
+}
+
+func NewText() *Text {
+	return &Text{}
+}
+
+// Adds the rows for the value
+func (t *Text) One(value jco.Value, metavar string) {
+	t.values = append(t.values, value)
+	t.metavars = append(t.metavars, metavar)
+}
+
+// Writes the rendered table
+func (t *Text) RenderTo(writer io.Writer) error {
+	_, err := io.WriteString(writer, t.String())
+	return err
+}
+
+func (t *Text) SetFormat(format Format) {
+	t.format = format
+}
+
+// Returns the rendered table, with the formula column naming the value on the first of its rows
+func (t *Text) String() string {
+	grid := [][]string{{"FORMULA", "|", "ENCODING", "TEXT"}}
+	for v, value := range t.values {
+		for i, row := range textRows(value) {
+			formula := ""
+			if i == 0 {
+				formula = t.metavars[v]
+			}
+			grid = append(grid, []string{formula, "|", row[0], row[1]})
+		}
+	}
+	return RenderGrid(grid, 1, t.format.Color)
+}
@@ -0,0 +1,38 @@
+package table
+
+import (
+	"github.com/jonathangjertsen/jco-go/jco"
+	"strings"
+	"testing"
+)
+
+func TestText(t *testing.T) {
+	value, _ := jco.Parse("0xe9", 16)
+	table := NewText()
+	table.One(value, "x")
+
+	want := []string{
+		"   FORMULA   |    ENCODING          TEXT",
+		"         x   |       ASCII            ..",
+		"             |     Latin-1            .é",
+		"             |    C string    \"\\x00\\xe9\"",
+		"             |   Codepoint      U+00E9 é",
+		"             |       UTF-8         c3 a9",
+		"             |    UTF-16BE         00 e9",
+		"             |    UTF-16LE         e9 00",
+		"             |    UTF-32BE   00 00 00 e9",
+		"             |    UTF-32LE   e9 00 00 00",
+	}
+	have := strings.Split(strings.TrimSuffix(table.String(), "\n"), "\n")
+	if strings.Join(have, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Want\n%s\nhave\n%s", strings.Join(want, "\n"), strings.Join(have, "\n"))
+	}
+
+	// Values which are not codepoints stop after the reason
+	above, _ := jco.Parse("0x110000", 32)
+	table = NewText()
+	table.One(above, "y")
+	if !strings.HasSuffix(table.String(), "Codepoint   none, above U+10FFFF\n") {
+		t.Errorf("Want no codepoint, have\n%s", table.String())
+	}
+}
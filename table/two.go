@@ -2,23 +2,28 @@ package table
 
 import (
 	"fmt"
+	"github.com/jonathangjertsen/jco-go/jco"
 	"github.com/jonathangjertsen/jco-go/ops"
 )
 
-func (t *Table) Two(a []byte, b []byte, metavar1 string, metavar2 string) {
-	t.Add(fmt.Sprintf("      %s", metavar1), a)
-	t.Add(fmt.Sprintf("      %s", metavar2), b)
-	t.Add(fmt.Sprintf("%s  + %s", metavar1, metavar2), ops.Add(a, b))
-	t.Add(fmt.Sprintf("%s  | %s", metavar1, metavar2), ops.Or(a, b))
-	t.Add(fmt.Sprintf("%s  & %s", metavar1, metavar2), ops.And(a, b))
-	t.Add(fmt.Sprintf("%s  ^ %s", metavar1, metavar2), ops.Xor(a, b))
-	t.Add(fmt.Sprintf("%s ^~ %s", metavar1, metavar2), ops.Xor(a, ops.Not(b)))
-	t.Add(fmt.Sprintf("%s  - %s", metavar1, metavar2), ops.Subtract(a, b))
-	t.Add(fmt.Sprintf("%s &~ %s", metavar1, metavar2), ops.And(a, ops.Not(b)))
-	t.Add(fmt.Sprintf("%s >> %s", metavar1, metavar2), ops.ShiftLeft(a, b))
-	t.Add(fmt.Sprintf("%s << %s", metavar1, metavar2), ops.ShiftRight(a, b))
-	t.Add(fmt.Sprintf("%s  - %s", metavar2, metavar1), ops.Subtract(b, a))
-	t.Add(fmt.Sprintf("%s &~ %s", metavar2, metavar1), ops.And(b, ops.Not(a)))
-	t.Add(fmt.Sprintf("%s >> %s", metavar2, metavar1), ops.ShiftLeft(b, a))
-	t.Add(fmt.Sprintf("%s << %s", metavar2, metavar1), ops.ShiftRight(b, a))
+func (t *Table) Two(a jco.Value, b jco.Value, metavar1 string, metavar2 string) {
+	selected := t.operations.ApplyDefaults(ops.OperationNames(2), ops.DefaultOperationNames(2))
+	for _, name := range selected {
+		op, _ := ops.LookupOperation(name, 2)
+		result, truncated, _ := a.Apply(name, b)
+		t.Add(Row{name, []string{metavar1, metavar2}, fmt.Sprintf(op.Formula, metavar1, metavar2), result, truncated, invalid(a, name, b)})
+	}
+	for _, name := range selected {
+		op, _ := ops.LookupOperation(name, 2)
+		if op.Swappable {
+			result, truncated, _ := b.Apply(name, a)
+			t.Add(Row{name, []string{metavar2, metavar1}, fmt.Sprintf(op.Formula, metavar2, metavar1), result, truncated, invalid(b, name, a)})
+		}
+	}
+}
+
+// Adds the rows for two numbers like Two, after converting both to the type, e.g. with C's promotion rules.
+// Each conversion that changes the type of an operand gets a row, so that sign and zero extension can be seen
+func (t *Table) TwoAs(a jco.Value, b jco.Value, metavar1 string, metavar2 string, to jco.Type) {
+	t.Two(t.convert(a, metavar1, to), t.convert(b, metavar2, to), metavar1, metavar2)
 }
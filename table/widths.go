@@ -0,0 +1,137 @@
+package table
+
+import (
+	"fmt"
+	"github.com/jonathangjertsen/jco-go/jco"
+	"io"
+)
+
+// A table which shows the same rows at several bit widths side by side, with one group of columns per width.
+// The values are shown as signed, so that the decimal column shows how the sign depends on the width
+type Widths struct {
+	tables []*Table
+}
+
+func NewWidths(widths []uint) *Widths {
+	w := &Widths{}
+	for _, bits := range widths {
+		w.tables = append(w.tables, NewTable(bits))
+	}
+	return w
+}
+
+// Adds rows to the table of each width, with the values sign- or zero-extended or truncated to that width.
+// If a value had to be truncated, every row for that width is marked as truncated
+func (w *Widths) add(values []jco.Value, fill func(t *Table, values []jco.Value)) {
+	for _, t := range w.tables {
+		resized := []jco.Value{}
+		truncated := false
+		for _, value := range values {
+			r, cut, _ := value.Resize(8 * t.bytes)
+			resized = append(resized, r.AsSigned())
+			truncated = truncated || cut
+		}
+		start := len(t.rows)
+		fill(t, resized)
+		for i := start; i < len(t.rows); i++ {
+			t.rows[i].Truncated = t.rows[i].Truncated || truncated
+		}
+	}
+}
+
+// Adds the rows for one number at every width, like Table.One
+func (w *Widths) One(a jco.Value, metavar string) {
+	w.add([]jco.Value{a}, func(t *Table, values []jco.Value) {
+		t.One(values[0], metavar)
+	})
+}
+
+// Writes the rendered table
+func (w *Widths) RenderTo(writer io.Writer) error {
+	_, err := io.WriteString(writer, w.String())
+	return err
+}
+
+func (w *Widths) SetColumns(columns Selection) {
+	for _, t := range w.tables {
+		t.SetColumns(columns)
+	}
+}
+
+func (w *Widths) SetFormat(format Format) {
+	for _, t := range w.tables {
+		t.SetFormat(format)
+	}
+}
+
+func (w *Widths) SetOperations(operations Selection) {
+	for _, t := range w.tables {
+		t.SetOperations(operations)
+	}
+}
+
+// Returns the rendered table. The formula column comes first, followed by a group with the other selected
+// columns for each width, labelled with the name of the signed type of that width
+func (w *Widths) String() string {
+	if len(w.tables) == 0 {
+		return ""
+	}
+	first := w.tables[0]
+	format := first.format
+	rows := [][]string{{}, {}}
+	if format.Ruler {
+		rows = append(rows, []string{})
+	}
+	for range first.rows {
+		rows = append(rows, []string{})
+	}
+	if contains(first.Columns(), "formula") {
+		rows[1] = []string{"FORMULA"}
+		rows[0] = []string{""}
+		if format.Ruler {
+			rows[2] = []string{""}
+		}
+		for i, row := range first.rows {
+			rows[len(rows)-len(first.rows)+i] = []string{row.Formula}
+		}
+	}
+
+	for _, t := range w.tables {
+		// The formula is already in the first column, so only the value columns are added for each width
+		columns := []int{}
+		for _, c := range t.selectedColumns() {
+			if c != COLUMN_FORMULA && c != COLUMN_SEPARATOR {
+				columns = append(columns, c)
+			}
+		}
+		groupRows := [][N_COLUMNS]string{{}, t.header()}
+		groupRows[0][COLUMN_SEPARATOR] = "|"
+		if len(columns) > 0 {
+			groupRows[0][columns[0]] = fmt.Sprintf("int%d", 8*t.bytes)
+		}
+		if format.Ruler {
+			groupRows = append(groupRows, t.ruler())
+		}
+		for _, row := range t.rows {
+			groupRows = append(groupRows, t.cells(row, columns))
+		}
+		for r, groupRow := range groupRows {
+			rows[r] = append(rows[r], groupRow[COLUMN_SEPARATOR])
+			for _, c := range columns {
+				rows[r] = append(rows[r], groupRow[c])
+			}
+		}
+	}
+	notes := ""
+	for _, t := range w.tables {
+		notes += t.notes(fmt.Sprintf(" as int%d", 8*t.bytes))
+	}
+	return RenderGrid(rows, 2, format.Color) + notes
+}
+
+// Adds the rows for two numbers at every width, like Table.Two
+func (w *Widths) Two(a jco.Value, b jco.Value, metavar1 string, metavar2 string) {
+	w.add([]jco.Value{a, b}, func(t *Table, values []jco.Value) {
+		t.Two(values[0], values[1], metavar1, metavar2)
+	})
+}
@@ -0,0 +1,26 @@
+package table
+
+import (
+	"github.com/jonathangjertsen/jco-go/jco"
+	"strings"
+	"testing"
+)
+
+func TestWidths(t *testing.T) {
+	value, _ := jco.Parse("0xfff3", 32)
+	table := NewWidths([]uint{8, 16, 32})
+	table.SetOperations(ParseSelection("value,clz"))
+	table.SetColumns(ParseSelection("formula,dec"))
+	table.One(value, "x")
+
+	want := []string{
+		"             |      int8   |     int16   |     int32",
+		"   FORMULA   |   DECIMAL   |   DECIMAL   |   DECIMAL",
+		"        x    |      *-13   |       -13   |     65523",
+		"    clz(x)   |        *0   |         0   |        16",
+	}
+	have := strings.Split(strings.TrimSuffix(table.String(), "\n"), "\n")
+	if strings.Join(have, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Want\n%s\nhave\n%s", strings.Join(want, "\n"), strings.Join(have, "\n"))
+	}
+}